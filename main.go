@@ -25,14 +25,19 @@ THE SOFTWARE.
 package main
 
 import (
+	"fmt"
 	"github.com/bit-fever/core/boot"
 	"github.com/bit-fever/core/msg"
 	"github.com/bit-fever/core/req"
 	"github.com/bit-fever/storage-manager/pkg/app"
 	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/backup"
 	"github.com/bit-fever/storage-manager/pkg/process/messaging/inventory"
+	"github.com/bit-fever/storage-manager/pkg/process/purger"
+	"github.com/bit-fever/storage-manager/pkg/process/scrubber"
 	"github.com/bit-fever/storage-manager/pkg/service"
 	"log/slog"
+	"os"
 )
 
 //=============================================================================
@@ -44,16 +49,53 @@ const component = "storage-manager"
 func main() {
 	cfg := &app.Config{}
 	boot.ReadConfig(component, cfg)
+
+	if backup.IsCommand(os.Args[1:]) {
+		runCommand(cfg, os.Args[1:])
+		return
+	}
+
 	logger := boot.InitLogger(component, &cfg.Application)
 	engine := boot.InitEngine(logger,    &cfg.Application)
 	initClients()
 	backend.InitStorage(cfg)
+	recoverStaging()
 	msg.InitMessaging(&cfg.Messaging)
 	service.Init(engine, cfg, logger)
 	inventory.InitMessageListener()
+	purger.InitTrashPurger(cfg)
+	scrubber.InitScrubber(cfg)
 	boot.RunHttpServer(engine, &cfg.Application)
 }
 
+//=============================================================================
+//=== Backup and restore from the command line, without starting the server
+
+func runCommand(cfg *app.Config, args []string) {
+	backend.InitStorage(cfg)
+
+	err := backup.RunCommand(args, os.Stdout, backup.GetMaxSize(&cfg.Storage))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+//=============================================================================
+//=== Runs before the server starts, so that no request sees a transaction
+//=== left half applied by a stopped process
+
+func recoverStaging() {
+	slog.Info("Recovering staging areas...")
+
+	count, err := backend.RecoverStaging()
+	if err != nil {
+		slog.Error("recoverStaging: Cannot clean the staging areas", "removed", count, "error", err)
+	} else if count > 0 {
+		slog.Info("recoverStaging: Stale staging areas removed", "removed", count)
+	}
+}
+
 //=============================================================================
 
 func initClients() {
@@ -29,7 +29,33 @@ import "github.com/bit-fever/core"
 //=============================================================================
 
 type Storage struct {
-	Folder string
+	Driver    string
+	Folder    string
+	Bucket    string
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+
+	MaxCodeSize   int64
+	MaxReportSize int64
+	MaxImageSize  int64
+	MaxBackupSize int64
+
+	CacheControl  string
+
+	//--- Equity chart snapshots kept per chart type (0 = no history)
+	ChartHistory  int
+
+	//--- Client ids (azp claim) allowed to write as Service (empty = any)
+	ServiceClients []string
+
+	//--- Days a deleted trading system stays in the trash (0 = default)
+	TrashRetentionDays int
+
+	//--- Hours between two scrubs of the stored files (0 = default)
+	ScrubIntervalHours int
 }
 
 //=============================================================================
@@ -29,9 +29,9 @@ import (
 	"github.com/bit-fever/core"
 	"github.com/bit-fever/storage-manager/pkg/app"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 //=============================================================================
@@ -44,13 +44,22 @@ const (
 	InfoFile    = "info.json"
 	DocFile     = "documentation.txt"
 	EquityChart = "equity-chart.png"
+
+	ChartFormatPNG  = "png"
+	ChartFormatSVG  = "svg"
+	ChartFormatWebP = "webp"
+
+	equityChartBase = "equity-chart"
 )
 
 var Dirs = []string{ Code, Image, Report }
 
+//--- Charts of the same type can be stored in several formats
+var ChartFormats = []string{ ChartFormatPNG, ChartFormatSVG, ChartFormatWebP }
+
 //=============================================================================
 
-var folder         string
+var store          Store
 var defEquityChart []byte
 
 //=============================================================================
@@ -60,15 +69,25 @@ var defEquityChart []byte
 //=============================================================================
 
 func InitStorage(cfg *app.Config) {
-	folder = cfg.Storage.Folder
-
-	err := os.MkdirAll(folder, 0700)
+	var err error
+	store, err = newStore(&cfg.Storage)
 	core.ExitIfError(err)
 
 	defEquityChart, err = os.ReadFile("default/"+ EquityChart)
 	core.ExitIfError(err)
 }
 
+//=============================================================================
+//=== Used by tests: replaces the configured driver with a volatile one
+
+func InitMemoryStorage(defaultChart []byte) *MemoryStore {
+	ms := NewMemoryStore()
+	store          = ms
+	defEquityChart = defaultChart
+
+	return ms
+}
+
 //=============================================================================
 //===
 //=== Public functionsk
@@ -76,13 +95,15 @@ func InitStorage(cfg *app.Config) {
 //=============================================================================
 
 func AddTradingSystem(ts *TradingSystem) error {
-	sId := strconv.Itoa(int(ts.Id))
-	path:= folder +"/"+ ts.Username +"/"+ sId +"/"
-
-	for _, dir := range Dirs {
-		err := os.MkdirAll(path + dir, 0700)
-		if err != nil {
-			return err
+	if dm, ok := store.(DirMaker); ok {
+		for _, dir := range Dirs {
+			p, err := buildPath(ts.Username, strconv.Itoa(int(ts.Id)), dir)
+			if err == nil {
+				err = dm.MakeDir(p)
+			}
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -101,19 +122,55 @@ func UpdateTradingSystem(ts *TradingSystem) error {
 }
 
 //=============================================================================
+//=== The trading system is moved to the trash of its user, see trash.go
 
 func DeleteTradingSystem(id uint, username string) error {
-	sId := strconv.Itoa(int(id))
-	return os.RemoveAll(folder +"/"+ username +"/"+ sId)
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	from := []string{ username, strconv.Itoa(int(id)) }
+	to   := []string{ username, TrashDir, buildTrashEntryName(id, time.Now()) }
+
+	err := moveTree(from, to)
+	if isNotExist(err) {
+		return nil
+	}
+
+	return err
 }
 
 //=============================================================================
 //=== Equity chart
 //=============================================================================
 
-func GetEquityChartTypes(username string, id uint) ([]string, error) {
+func GetEquityChartTypes(username string, id uint) ([]*EquityChartType, error) {
+	files,err := GetEquityChartFiles(username, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var types []*EquityChartType
+	index := map[string]*EquityChartType{}
+
+	for _, file := range files {
+		ect, ok := index[file.Type]
+		if !ok {
+			ect = &EquityChartType{ Type: file.Type }
+			index[file.Type] = ect
+			types = append(types, ect)
+		}
+
+		ect.Formats = append(ect.Formats, file.Format)
+	}
+
+	return types, nil
+}
+
+//=============================================================================
+
+func GetEquityChartFiles(username string, id uint) ([]EquityChartFile, error) {
 	path := []string{
-		folder,
 		username,
 		strconv.Itoa(int(id)),
 	}
@@ -124,25 +181,26 @@ func GetEquityChartTypes(username string, id uint) ([]string, error) {
 		return nil, err
 	}
 
-	var types []string
+	var list []EquityChartFile
 
 	for _, file := range files {
-		if isEquityChartName(file.Name()) {
-			types = append(types, getChartType(file.Name()))
+		if !file.IsDir {
+			if chartType, format, ok := parseEquityChartName(file.Name); ok {
+				list = append(list, EquityChartFile{ FileInfo: file, Type: chartType, Format: format })
+			}
 		}
 	}
 
-	return types, nil
+	return list, nil
 }
 
 //=============================================================================
 
-func ReadEquityChart(username string, id uint, chartType string) ([]byte,error) {
+func ReadEquityChart(username string, id uint, chartType string, format string) ([]byte,error) {
 	path := []string{
-		folder,
 		username,
 		strconv.Itoa(int(id)),
-		buildEquityChartName(chartType),
+		buildEquityChartName(chartType, format),
 	}
 
 	return readFile(path...)
@@ -150,28 +208,55 @@ func ReadEquityChart(username string, id uint, chartType string) ([]byte,error)
 
 //=============================================================================
 
-func WriteEquityChart(username string, id uint, data []byte, chartType string) error {
-	path := []string{
-		folder,
-		username,
-		strconv.Itoa(int(id)),
-		buildEquityChartName(chartType),
+func GetEquityChartInfo(username string, id uint, chartType string, format string) (*FileInfo, error) {
+	return statFile(username, strconv.Itoa(int(id)), buildEquityChartName(chartType, format))
+}
+
+//=============================================================================
+
+func WriteEquityChart(username string, id uint, data []byte, chartType string, format string, ecm *EquityChartMetadata, history int) error {
+	cw := &ChartWrite{
+		Type    : chartType,
+		Format  : format,
+		Data    : data,
+		Metadata: ecm,
 	}
 
-	return writeFile(data, path...)
+	return WriteEquityCharts(username, id, []*ChartWrite{ cw }, history)
 }
 
 //=============================================================================
 
-func DeleteEquityChart(username string, id uint, chartType string) error {
+func DeleteEquityChart(username string, id uint, chartType string, format string) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
 	path := []string{
-		folder,
 		username,
 		strconv.Itoa(int(id)),
-		buildEquityChartName(chartType),
+		buildEquityChartName(chartType, format),
 	}
 
-	return deleteFile(path...)
+	err := deleteFile(path...)
+	if err != nil {
+		return err
+	}
+
+	path[2] += MetadataSuffix
+
+	err = deleteFile(path...)
+	if err != nil && !isNotExist(err) {
+		return err
+	}
+
+	return DeleteVariants(username, id, VariantEquityChart, chartType)
+}
+
+//=============================================================================
+//=== Returns nil if the chart has no metadata
+
+func ReadEquityChartMetadata(username string, id uint, chartType string, format string) (*EquityChartMetadata, error) {
+	return readChartMetadata(username, strconv.Itoa(int(id)), buildEquityChartName(chartType, format) + MetadataSuffix)
 }
 
 //=============================================================================
@@ -186,7 +271,6 @@ func GetDefaultEquityChart() []byte {
 
 func GetTradingSystemDoc(username string, id uint) (string, error) {
 	path := []string{
-		folder,
 		username,
 		strconv.Itoa(int(id)),
 		DocFile,
@@ -202,9 +286,14 @@ func GetTradingSystemDoc(username string, id uint) (string, error) {
 
 //=============================================================================
 
+func GetTradingSystemDocInfo(username string, id uint) (*FileInfo, error) {
+	return statFile(username, strconv.Itoa(int(id)), DocFile)
+}
+
+//=============================================================================
+
 func SetTradingSystemDoc(username string, id uint, doc string) error {
 	path := []string{
-		folder,
 		username,
 		strconv.Itoa(int(id)),
 		DocFile,
@@ -213,13 +302,74 @@ func SetTradingSystemDoc(username string, id uint, doc string) error {
 	return writeFile([]byte(doc), path...)
 }
 
+//=============================================================================
+//=== Stores the documentation and keeps track of it in the history
+
+func SaveTradingSystemDoc(username string, id uint, doc string, r *Revision, expectedRev int) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	if expectedRev != AnyRevision {
+		current, err := GetTradingSystemDocCurrentRevision(username, id)
+		if err != nil {
+			return err
+		}
+
+		if current != expectedRev {
+			return ErrRevisionMismatch
+		}
+	}
+
+	history := buildDocHistoryPath(username, id)
+
+	err := addBaselineRevision([]string{ username, strconv.Itoa(int(id)), DocFile }, history...)
+	if err != nil {
+		return err
+	}
+
+	err = addRevision([]byte(doc), r, history...)
+	if err != nil {
+		return err
+	}
+
+	return SetTradingSystemDoc(username, id, doc)
+}
+
+//=============================================================================
+//=== Returns 0 when the documentation has never been saved
+
+func GetTradingSystemDocCurrentRevision(username string, id uint) (int, error) {
+	list, err := GetTradingSystemDocRevisions(username, id)
+	if err != nil || len(list) == 0 {
+		return 0, err
+	}
+
+	return list[len(list) -1].Revision, nil
+}
+
+//=============================================================================
+
+func GetTradingSystemDocRevisions(username string, id uint) ([]*Revision, error) {
+	return readRevisions(buildDocHistoryPath(username, id)...)
+}
+
+//=============================================================================
+
+func GetTradingSystemDocRevision(username string, id uint, rev int) (*Revision, string, error) {
+	r, data, err := readRevision(rev, buildDocHistoryPath(username, id)...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return r, string(data), nil
+}
+
 //=============================================================================
 //=== Information
 //=============================================================================
 
 func GetTradingSystemInfo(username string, id uint) (*TradingSystem, error) {
 	path := []string{
-		folder,
 		username,
 		strconv.Itoa(int(id)),
 		InfoFile,
@@ -241,9 +391,14 @@ func GetTradingSystemInfo(username string, id uint) (*TradingSystem, error) {
 
 //=============================================================================
 
+func GetTradingSystemInfoFile(username string, id uint) (*FileInfo, error) {
+	return statFile(username, strconv.Itoa(int(id)), InfoFile)
+}
+
+//=============================================================================
+
 func SetTradingSystemInfo(ts *TradingSystem) error {
 	path := []string{
-		folder,
 		ts.Username,
 		strconv.Itoa(int(ts.Id)),
 		InfoFile,
@@ -263,64 +418,168 @@ func SetTradingSystemInfo(ts *TradingSystem) error {
 //===
 //=============================================================================
 
-func getFiles(path ...string) ([]os.DirEntry, error) {
-	dir := filepath.Join(path...)
-	return os.ReadDir(dir)
+func getFiles(path ...string) ([]FileInfo, error) {
+	p, err := buildPath(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.List(p)
 }
 
 //=============================================================================
 
 func readFile(path ...string) ([]byte, error) {
-	file := filepath.Join(path...)
-	return os.ReadFile(file)
+	p, err := buildPath(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Get(p)
 }
 
 //=============================================================================
 
 func writeFile(data []byte, path ...string) error {
-	file := filepath.Join(path...)
-	err  := os.WriteFile(file +".temp", data, 0600)
+	p, err := buildPath(path...)
+	if err != nil {
+		return err
+	}
+
+	if !isChecksummed(path) {
+		return store.Put(p, data)
+	}
+
+	unlock := lockChecksums(path[0], path[1])
+	defer unlock()
 
+	err = store.Put(p, data)
 	if err != nil {
 		return err
 	}
 
-	_,err = os.Stat(file)
+	return updateChecksums(path, setChecksum(data))
+}
 
-	if err == nil {
-		err = os.Remove(file)
-		if err != nil {
-			return err
-		}
+//=============================================================================
+//=== Replaces the file 'to' with the file 'from', whose content is data.
+//=== Drivers without a rename primitive get the data written again.
+
+func replaceFile(data []byte, from []string, to []string) error {
+	renamer, ok := store.(Renamer)
+	if !ok {
+		return writeFile(data, to...)
 	}
 
-	return os.Rename(file +".temp", file)
+	src, err := buildPath(from...)
+	if err != nil {
+		return err
+	}
+
+	dst, err := buildPath(to...)
+	if err != nil {
+		return err
+	}
+
+	if !isChecksummed(to) {
+		return renamer.Rename(src, dst)
+	}
+
+	unlock := lockChecksums(to[0], to[1])
+	defer unlock()
+
+	err = renamer.Rename(src, dst)
+	if err != nil {
+		return err
+	}
+
+	return updateChecksums(to, setChecksum(data))
 }
 
 //=============================================================================
 
 func deleteFile(path ...string) error {
-	file := filepath.Join(path...)
-	return os.Remove(file)
+	p, err := buildPath(path...)
+	if err != nil {
+		return err
+	}
+
+	if !isChecksummed(path) {
+		return store.Delete(p)
+	}
+
+	unlock := lockChecksums(path[0], path[1])
+	defer unlock()
+
+	err = store.Delete(p)
+	if err != nil {
+		return err
+	}
+
+	return updateChecksums(path, removeChecksum)
 }
 
 //=============================================================================
 
-func buildEquityChartName(chartType string) string {
-	return chartType +"-"+ EquityChart
+func statFile(path ...string) (*FileInfo, error) {
+	p, err := buildPath(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.Stat(p)
 }
 
 //=============================================================================
+//=== Returns nil if the sidecar does not exist
 
-func isEquityChartName(fileName string) bool {
-	return strings.HasSuffix(fileName, "-"+ EquityChart)
+func readChartMetadata(path ...string) (*EquityChartMetadata, error) {
+	data, err := readFile(path...)
+	if err != nil {
+		if isNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ecm := EquityChartMetadata{}
+	err = json.Unmarshal(data, &ecm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecm, nil
 }
 
 //=============================================================================
 
-func getChartType(fileName string) string {
-	index := strings.Index(fileName, "-"+ EquityChart)
-	return fileName[0:index]
+func buildDocHistoryPath(username string, id uint) []string {
+	return []string{
+		username,
+		strconv.Itoa(int(id)),
+		HistoryDir,
+		"documentation",
+	}
+}
+
+//=============================================================================
+
+func buildEquityChartName(chartType string, format string) string {
+	return chartType +"-"+ equityChartBase +"."+ format
+}
+
+//=============================================================================
+//=== Splits <type>-equity-chart.<format> into its type and format
+
+func parseEquityChartName(fileName string) (string, string, bool) {
+	for _, format := range ChartFormats {
+		suffix := "-"+ equityChartBase +"."+ format
+		if strings.HasSuffix(fileName, suffix) {
+			return strings.TrimSuffix(fileName, suffix), format, true
+		}
+	}
+
+	return "", "", false
 }
 
 //=============================================================================
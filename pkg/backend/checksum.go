@@ -0,0 +1,179 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+//=== Every file stored in a trading system folder has its SHA-256 recorded in
+//=== <username>/<id>/checksums.json, keyed by its path relative to the
+//=== folder. The manifest is updated by writeFile, deleteFile and deleteTree
+//=== and travels with the folder when it is moved to the trash and back.
+//=== Caches and staging areas can be rebuilt and are not tracked.
+//=============================================================================
+
+const ChecksumFile = "checksums.json"
+
+//=============================================================================
+
+type Checksum struct {
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+//=============================================================================
+
+func GetChecksums(username string, id uint) (map[string]*Checksum, error) {
+	unlock := lockChecksums(username, strconv.Itoa(int(id)))
+	defer unlock()
+
+	return readChecksums(username, strconv.Itoa(int(id)))
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func newChecksum(data []byte, now time.Time) *Checksum {
+	sum := sha256.Sum256(data)
+
+	return &Checksum{
+		SHA256   : hex.EncodeToString(sum[:]),
+		Size     : int64(len(data)),
+		UpdatedAt: now.UTC(),
+	}
+}
+
+//=============================================================================
+//=== Returns true if the path is a file of a trading system folder whose
+//=== checksum must be recorded
+
+func isChecksummed(path []string) bool {
+	if len(path) < 3 || !isTradingSystemDir(path[1]) {
+		return false
+	}
+
+	switch path[2] {
+	case ChecksumFile, CacheDir, StagingDir:
+		return false
+	}
+
+	return true
+}
+
+//=============================================================================
+//=== The manifest is missing until the first tracked write
+
+func readChecksums(username string, id string) (map[string]*Checksum, error) {
+	p, err := buildPath(username, id, ChecksumFile)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := store.Get(p)
+	if err != nil {
+		if isNotExist(err) {
+			return map[string]*Checksum{}, nil
+		}
+		return nil, err
+	}
+
+	checksums := map[string]*Checksum{}
+	err = json.Unmarshal(data, &checksums)
+	if err != nil {
+		return nil, err
+	}
+
+	return checksums, nil
+}
+
+//=============================================================================
+//=== Goes straight to the store: writeFile would try to track the manifest
+
+func writeChecksums(username string, id string, checksums map[string]*Checksum) error {
+	p, err := buildPath(username, id, ChecksumFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(checksums)
+	if err != nil {
+		return err
+	}
+
+	return store.Put(p, data)
+}
+
+//=============================================================================
+//=== Applies a change to the manifest of the trading system of path. The
+//=== checksum lock must be held by the caller.
+
+func updateChecksums(path []string, update func(checksums map[string]*Checksum, rel string)) error {
+	checksums, err := readChecksums(path[0], path[1])
+	if err != nil {
+		return err
+	}
+
+	update(checksums, strings.Join(path[2:], "/"))
+
+	return writeChecksums(path[0], path[1], checksums)
+}
+
+//=============================================================================
+
+func setChecksum(data []byte) func(map[string]*Checksum, string) {
+	return func(checksums map[string]*Checksum, rel string) {
+		checksums[rel] = newChecksum(data, time.Now())
+	}
+}
+
+//=============================================================================
+
+func removeChecksum(checksums map[string]*Checksum, rel string) {
+	delete(checksums, rel)
+}
+
+//=============================================================================
+
+func removeChecksumTree(checksums map[string]*Checksum, rel string) {
+	for name := range checksums {
+		if strings.HasPrefix(name, rel +"/") {
+			delete(checksums, name)
+		}
+	}
+}
+
+//=============================================================================
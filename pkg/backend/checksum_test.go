@@ -0,0 +1,206 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+func TestChecksums(t *testing.T) {
+	InitMemoryStorage(nil)
+
+	if err := AddTradingSystem(&TradingSystem{ Id: 7, Username: "john", Name: "Breakout" }); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteCodeFile("john", 7, "main.el", []byte("buy next bar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEquityChart("john", 7, []byte("chart"), "daily", ChartFormatPNG, nil, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteVariant("john", 7, VariantEquityChart, "daily", "thumb", []byte("small")); err != nil {
+		t.Fatal(err)
+	}
+
+	checksums, err := GetChecksums("john", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := newChecksum([]byte("buy next bar"), time.Now())
+	if code := checksums["code/main.el"]; code == nil || code.Size != expected.Size || code.SHA256 != expected.SHA256 {
+		t.Errorf("GetChecksums: bad code checksum %+v", code)
+	}
+
+	for _, rel := range []string{ InfoFile, DocFile, "daily-equity-chart.png" } {
+		if checksums[rel] == nil {
+			t.Errorf("GetChecksums: missing %s", rel)
+		}
+	}
+
+	//--- Charts snapshots are tracked, caches and staging areas are not
+	snapshots := 0
+	for rel := range checksums {
+		switch {
+		case strings.HasPrefix(rel, HistoryDir +"/"):
+			snapshots++
+		case strings.HasPrefix(rel, CacheDir +"/"), strings.HasPrefix(rel, StagingDir +"/"):
+			t.Errorf("GetChecksums: %s should not be tracked", rel)
+		}
+	}
+	if snapshots != 1 {
+		t.Errorf("GetChecksums: expected 1 snapshot, got %d in %v", snapshots, checksums)
+	}
+
+	if err = DeleteCodeFile("john", 7, "main.el"); err != nil {
+		t.Fatal(err)
+	}
+	if err = deleteTree("john", "7", HistoryDir); err != nil {
+		t.Fatal(err)
+	}
+
+	checksums, _ = GetChecksums("john", 7)
+	if len(checksums) != 3 {
+		t.Errorf("GetChecksums: deleted files still tracked %v", checksums)
+	}
+
+	//--- The manifest follows the trading system in the trash
+	if err = DeleteTradingSystem(7, "john"); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := GetTrashEntries("john")
+	if _, err = RestoreTradingSystem("john", list[0].Entry); err != nil {
+		t.Fatal(err)
+	}
+	if checksums, _ = GetChecksums("john", 7); len(checksums) != 3 {
+		t.Errorf("GetChecksums: manifest lost by the trash %v", checksums)
+	}
+}
+
+//=============================================================================
+
+func TestScrub(t *testing.T) {
+	ms := InitMemoryStorage(nil)
+
+	for _, ts := range []*TradingSystem{
+		{ Id: 7, Username: "john", Name: "Breakout"       },
+		{ Id: 8, Username: "jane", Name: "Mean reversion" },
+	} {
+		if err := AddTradingSystem(ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := WriteCodeFile("john", 7, "main.el", []byte("buy next bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Scrub()
+	if err != nil || report.TradingSystems != 2 || report.Files != 5 || report.Adopted != 0 || len(report.Issues) != 0 {
+		t.Fatalf("Scrub: got %+v, %v", report, err)
+	}
+	if GetLastScrubReport() != report {
+		t.Errorf("GetLastScrubReport: report not kept")
+	}
+
+	//--- Changes behind the back of the backend
+
+	_ = ms.Put("john/7/code/main.el", []byte("sell next bar"))
+	_ = ms.Delete("jane/8/"+ DocFile)
+	_ = ms.Put("jane/8/code/old.el", []byte("written before checksums"))
+
+	report, err = Scrub()
+	if err != nil || report.Adopted != 1 || len(report.Issues) != 2 {
+		t.Fatalf("Scrub: got %+v, %v", report, err)
+	}
+
+	for _, i := range report.Issues {
+		switch {
+		case i.Username == "john" && i.Id == 7 && i.Path == "code/main.el":
+			if i.Status != ScrubMismatch || i.Expected == i.Actual || i.Actual == "" {
+				t.Errorf("Scrub: bad mismatch %+v", i)
+			}
+		case i.Username == "jane" && i.Id == 8 && i.Path == DocFile:
+			if i.Status != ScrubMissing {
+				t.Errorf("Scrub: bad missing file %+v", i)
+			}
+		default:
+			t.Errorf("Scrub: unexpected issue %+v", i)
+		}
+	}
+
+	if checksums, _ := GetChecksums("jane", 8); checksums["code/old.el"] == nil {
+		t.Errorf("Scrub: untracked file not adopted")
+	}
+
+	//--- Rewriting the files through the backend fixes the issues
+	if err = WriteCodeFile("john", 7, "main.el", []byte("sell next bar")); err != nil {
+		t.Fatal(err)
+	}
+	if err = SetTradingSystemDoc("jane", 8, ""); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = Scrub(); err != nil || len(report.Issues) != 0 || report.Adopted != 0 {
+		t.Errorf("Scrub: got %+v, %v", report, err)
+	}
+
+	//--- The saved report survives a restart and is not taken for a user
+
+	if _, err = ms.Get(ScrubReportFile); err != nil {
+		t.Fatalf("Scrub: report not saved, %v", err)
+	}
+
+	lastScrub.report = nil
+
+	if err = LoadLastScrubReport(); err != nil {
+		t.Fatalf("LoadLastScrubReport: %v", err)
+	}
+
+	loaded := GetLastScrubReport()
+	if loaded == nil || loaded.TradingSystems != 2 || loaded.Files != report.Files || !loaded.StartedAt.Equal(report.StartedAt) {
+		t.Errorf("LoadLastScrubReport: got %+v", loaded)
+	}
+	if report, err = Scrub(); err != nil || report.TradingSystems != 2 {
+		t.Errorf("Scrub: got %+v, %v", report, err)
+	}
+}
+
+//=============================================================================
+
+func TestLoadLastScrubReport_Missing(t *testing.T) {
+	InitMemoryStorage(nil)
+	lastScrub.report = nil
+
+	if err := LoadLastScrubReport(); err != nil || GetLastScrubReport() != nil {
+		t.Errorf("LoadLastScrubReport: got %+v, %v", GetLastScrubReport(), err)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,186 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+
+const DeploymentFile = "deployment.json"
+
+//=============================================================================
+//=== Strategy source files, stored in the 'code' folder
+//=============================================================================
+
+func GetCodeFiles(username string, id uint) ([]FileInfo, error) {
+	return getDirFiles(username, id, Code)
+}
+
+//=============================================================================
+
+func GetCodeFileInfo(username string, id uint, name string) (*FileInfo, error) {
+	return statFile(username, strconv.Itoa(int(id)), Code, name)
+}
+
+//=============================================================================
+
+func ReadCodeFile(username string, id uint, name string) ([]byte, error) {
+	return readFile(username, strconv.Itoa(int(id)), Code, name)
+}
+
+//=============================================================================
+
+func WriteCodeFile(username string, id uint, name string, data []byte) error {
+	return writeFile(data, username, strconv.Itoa(int(id)), Code, name)
+}
+
+//=============================================================================
+
+func DeleteCodeFile(username string, id uint, name string) error {
+	return deleteFile(username, strconv.Itoa(int(id)), Code, name)
+}
+
+//=============================================================================
+//=== Every upload creates an immutable revision in the file's history
+
+func SaveCodeFile(username string, id uint, name string, data []byte, r *Revision) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	err := addRevision(data, r, buildCodeHistoryPath(username, id, name)...)
+	if err != nil {
+		return err
+	}
+
+	return WriteCodeFile(username, id, name, data)
+}
+
+//=============================================================================
+
+func GetCodeRevisions(username string, id uint, name string) ([]*Revision, error) {
+	return readRevisions(buildCodeHistoryPath(username, id, name)...)
+}
+
+//=============================================================================
+
+func GetCodeRevision(username string, id uint, name string, rev int) (*Revision, []byte, error) {
+	return readRevision(rev, buildCodeHistoryPath(username, id, name)...)
+}
+
+//=============================================================================
+
+func TagCodeRevision(username string, id uint, name string, rev int, tag string) (*Revision, error) {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	return tagRevision(rev, tag, buildCodeHistoryPath(username, id, name)...)
+}
+
+//=============================================================================
+//=== Returns nil if the file has never been deployed
+
+func GetCodeDeployment(username string, id uint, name string) (*Deployment, error) {
+	data, err := readFile(append(buildCodeHistoryPath(username, id, name), DeploymentFile)...)
+	if err != nil {
+		if isNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	d := Deployment{}
+	err = json.Unmarshal(data, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+//=============================================================================
+
+func SetCodeDeployment(username string, id uint, name string, d *Deployment) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	base := buildCodeHistoryPath(username, id, name)
+
+	_, _, err := readRevision(d.Revision, base...)
+	if err != nil {
+		return err
+	}
+
+	d.Timestamp = time.Now().UTC()
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(data, append(base, DeploymentFile)...)
+}
+
+//=============================================================================
+//=== Returns the regular files of one of the trading system's folders. A
+//=== missing folder (object storages have no empty folders) means no files.
+
+func getDirFiles(username string, id uint, dir string) ([]FileInfo, error) {
+	files, err := getFiles(username, strconv.Itoa(int(id)), dir)
+	if err != nil {
+		if isNotExist(err) {
+			return []FileInfo{}, nil
+		}
+		return nil, err
+	}
+
+	list := []FileInfo{}
+
+	for _, file := range files {
+		if !file.IsDir && !strings.HasSuffix(file.Name, ".temp") {
+			list = append(list, file)
+		}
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+
+func buildCodeHistoryPath(username string, id uint, name string) []string {
+	return []string{
+		username,
+		strconv.Itoa(int(id)),
+		HistoryDir,
+		Code,
+		name,
+	}
+}
+
+//=============================================================================
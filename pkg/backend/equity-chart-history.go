@@ -0,0 +1,183 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//=============================================================================
+//=== Snapshots of the equity charts, stored in history/equity-chart/<type>/
+//=== as <timestamp>.<format>, with the metadata sidecar of the chart, if any.
+//=== Snapshots are written together with the charts and only the most
+//=== recent ones are kept.
+//=============================================================================
+
+const snapshotLayout = "20060102T150405.000000000Z"
+
+//=============================================================================
+
+func GetEquityChartSnapshots(username string, id uint, chartType string) ([]*ChartSnapshot, error) {
+	files, err := getFiles(buildSnapshotPath(username, id, chartType)...)
+	if err != nil {
+		if isNotExist(err) {
+			return []*ChartSnapshot{}, nil
+		}
+		return nil, err
+	}
+
+	list := []*ChartSnapshot{}
+
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+
+		if timestamp, format, ok := parseSnapshotName(file.Name); ok {
+			list = append(list, &ChartSnapshot{
+				Timestamp: timestamp,
+				Format   : format,
+				Size     : file.Size,
+			})
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Timestamp.Before(list[j].Timestamp)
+	})
+
+	return list, nil
+}
+
+//=============================================================================
+
+func ReadEquityChartSnapshot(username string, id uint, chartType string, timestamp time.Time) (*ChartSnapshot, []byte, error) {
+	var err error
+
+	for _, format := range ChartFormats {
+		path := append(buildSnapshotPath(username, id, chartType), buildSnapshotName(timestamp, format))
+
+		var data []byte
+		data, err = readFile(path...)
+		if err == nil {
+			cs := &ChartSnapshot{
+				Timestamp: timestamp.UTC(),
+				Format   : format,
+				Size     : int64(len(data)),
+			}
+
+			return cs, data, nil
+		}
+
+		if !isNotExist(err) {
+			return nil, nil, err
+		}
+	}
+
+	return nil, nil, err
+}
+
+//=============================================================================
+//=== Returns nil if the snapshot has no metadata
+
+func ReadEquityChartSnapshotMetadata(username string, id uint, chartType string, cs *ChartSnapshot) (*EquityChartMetadata, error) {
+	path := append(buildSnapshotPath(username, id, chartType), buildSnapshotName(cs.Timestamp, cs.Format) + MetadataSuffix)
+	return readChartMetadata(path...)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func (cw *ChartWrite) addSnapshot(username string, id uint, timestamp time.Time, meta []byte) {
+	path := append(buildSnapshotPath(username, id, cw.Type), buildSnapshotName(timestamp, cw.Format))
+	cw.files = append(cw.files, &txFile{ path: path, data: cw.Data })
+
+	if meta != nil {
+		path = slices.Clone(path)
+		path[len(path) -1] += MetadataSuffix
+		cw.files = append(cw.files, &txFile{ path: path, data: meta })
+	}
+}
+
+//=============================================================================
+//=== Best effort: snapshots left behind are removed by the next write
+
+func pruneEquityChartSnapshots(username string, id uint, chartType string, keep int) {
+	keep = max(keep, 0)
+
+	list, err := GetEquityChartSnapshots(username, id, chartType)
+	if err != nil || len(list) <= keep {
+		return
+	}
+
+	for _, cs := range list[:len(list) -keep] {
+		path := append(buildSnapshotPath(username, id, chartType), buildSnapshotName(cs.Timestamp, cs.Format))
+		if deleteFile(path...) == nil {
+			path[len(path) -1] += MetadataSuffix
+			_ = deleteFile(path...)
+		}
+	}
+}
+
+//=============================================================================
+
+func buildSnapshotPath(username string, id uint, chartType string) []string {
+	return []string{ username, strconv.Itoa(int(id)), HistoryDir, equityChartBase, chartType }
+}
+
+//=============================================================================
+
+func buildSnapshotName(timestamp time.Time, format string) string {
+	return timestamp.UTC().Format(snapshotLayout) +"."+ format
+}
+
+//=============================================================================
+
+func parseSnapshotName(name string) (time.Time, string, bool) {
+	if len(name) <= len(snapshotLayout) +1 || name[len(snapshotLayout)] != '.' {
+		return time.Time{}, "", false
+	}
+
+	format := name[len(snapshotLayout) +1:]
+	if !slices.Contains(ChartFormats, format) {
+		return time.Time{}, "", false
+	}
+
+	timestamp, err := time.Parse(snapshotLayout, name[:len(snapshotLayout)])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+
+	return timestamp, format, true
+}
+
+//=============================================================================
@@ -0,0 +1,483 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+)
+
+//=============================================================================
+//=== Writes a set of equity charts, with their metadata sidecars, as a whole.
+//=== Charts are first staged in staging/<tx>/files/, so that the store
+//=== accepts every file before a live chart is touched. Then a journal is
+//=== written and the staged files are renamed over the live ones (drivers
+//=== without a rename primitive get them written again). If a write fails,
+//=== the files already written get their previous content back. If the
+//=== process stops after the journal has been written, RecoverStaging
+//=== completes the transaction; without a journal, the staging area is
+//=== simply removed. When history is greater than zero, a snapshot of each
+//=== chart is written too. Only the last 'history' snapshots are kept, so a
+//=== history of zero removes the snapshots of the written chart types.
+//=== Every format of a chart type is a file of its own: a write replaces
+//=== only the format it carries, the other ones are left as they are.
+//=============================================================================
+
+const (
+	StagingDir  = "staging"
+	JournalFile = "journal.json"
+
+	stagingFiles = "files"
+)
+
+const (
+	ChartPending    = "pending"
+	ChartWritten    = "written"
+	ChartFailed     = "failed"
+	ChartRolledBack = "rolledBack"
+)
+
+//=============================================================================
+//=== A nil Metadata removes the sidecar of a previous write
+
+type ChartWrite struct {
+	Type     string
+	Format   string
+	Data     []byte
+	Metadata *EquityChartMetadata
+	Status   string
+	Error    error
+
+	files []*txFile
+}
+
+//=============================================================================
+//=== A nil data means that the file must be deleted
+
+type txFile struct {
+	path     []string
+	staged   []string
+	data     []byte
+	previous []byte
+	existed  bool
+	written  bool
+}
+
+//=============================================================================
+//=== Paths are relative to the trading system folder
+
+type txJournal struct {
+	Types []string   `json:"types"`
+	Files []*txEntry `json:"files"`
+}
+
+//=============================================================================
+
+type txEntry struct {
+	Path   []string `json:"path"`
+	Delete bool     `json:"delete,omitempty"`
+}
+
+//=============================================================================
+
+func WriteEquityCharts(username string, id uint, charts []*ChartWrite, history int) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	now := time.Now()
+
+	for _, cw := range charts {
+		err := cw.prepare(username, id, now, history)
+		if err != nil {
+			cw.Status = ChartFailed
+			cw.Error  = err
+			return err
+		}
+	}
+
+	tx  := buildStagingPath(username, id, strconv.FormatInt(now.UnixNano(), 36))
+	err := stageEquityCharts(tx, charts)
+
+	if err == nil {
+		err = writeJournal(tx, charts)
+	}
+
+	if err == nil {
+		err = commitEquityCharts(tx, charts)
+	}
+
+	err = errors.Join(err, deleteTree(tx...))
+
+	if err == nil {
+		for _, cw := range charts {
+			pruneEquityChartSnapshots(username, id, cw.Type, history)
+		}
+	}
+
+	for _, cw := range charts {
+		if cw.Status == ChartWritten || cw.Status == ChartRolledBack {
+			err = errors.Join(err, DeleteVariants(username, id, VariantEquityChart, cw.Type))
+		}
+	}
+
+	return err
+}
+
+//=============================================================================
+//=== Removes the staging areas left by a stopped process, completing the
+//=== transactions that had reached their journal. Transactions in progress
+//=== are protected by the trading system lock. Returns the number of staging
+//=== areas removed.
+
+func RecoverStaging() (int, error) {
+	users, err := getUsers()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, username := range users {
+		files, err := getFiles(username)
+		if err != nil {
+			return count, err
+		}
+
+		for _, file := range files {
+			if !file.IsDir || !isTradingSystemDir(file.Name) {
+				continue
+			}
+
+			txs, err := getFiles(username, file.Name, StagingDir)
+			if err != nil {
+				if isNotExist(err) {
+					continue
+				}
+				return count, err
+			}
+
+			id, _ := strconv.ParseUint(file.Name, 10, 32)
+
+			for _, tx := range txs {
+				err = recoverTransaction(username, uint(id), tx.Name)
+				if err != nil {
+					return count, err
+				}
+
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func (cw *ChartWrite) prepare(username string, id uint, now time.Time, history int) error {
+	name := buildEquityChartName(cw.Type, cw.Format)
+
+	var meta []byte
+	if cw.Metadata != nil {
+		var err error
+		meta, err = json.Marshal(cw.Metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Status = ChartPending
+	cw.files  = []*txFile{
+		{ path: []string{ username, strconv.Itoa(int(id)), name },                  data: cw.Data },
+		{ path: []string{ username, strconv.Itoa(int(id)), name + MetadataSuffix }, data: meta    },
+	}
+
+	if history > 0 {
+		cw.addSnapshot(username, id, now, meta)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func stageEquityCharts(tx []string, charts []*ChartWrite) error {
+	for _, cw := range charts {
+		for _, file := range cw.files {
+			if file.data == nil {
+				continue
+			}
+
+			file.staged = buildStagedPath(tx, file.path[2:])
+
+			err := writeFile(file.data, file.staged...)
+			if err != nil {
+				cw.Status = ChartFailed
+				cw.Error  = err
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//=== Once the journal is written, the transaction must be completed
+
+func writeJournal(tx []string, charts []*ChartWrite) error {
+	j := &txJournal{}
+
+	for _, cw := range charts {
+		j.Types = append(j.Types, cw.Type)
+
+		for _, file := range cw.files {
+			j.Files = append(j.Files, &txEntry{ Path: file.path[2:], Delete: file.data == nil })
+		}
+	}
+
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(data, append(slices.Clone(tx), JournalFile)...)
+}
+
+//=============================================================================
+//=== The journal is removed before the rollback, so that the recovery never
+//=== completes a transaction that failed
+
+func commitEquityCharts(tx []string, charts []*ChartWrite) error {
+	for _, cw := range charts {
+		for _, file := range cw.files {
+			err := file.commit()
+			if err != nil {
+				cw.Status = ChartFailed
+				cw.Error  = err
+
+				err = errors.Join(err, deleteFile(append(slices.Clone(tx), JournalFile)...))
+				return errors.Join(err, rollbackEquityCharts(charts))
+			}
+		}
+
+		cw.Status = ChartWritten
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func rollbackEquityCharts(charts []*ChartWrite) error {
+	var errs []error
+
+	for _, cw := range charts {
+		restored := true
+
+		for _, file := range cw.files {
+			if err := file.rollback(); err != nil {
+				errs     = append(errs, err)
+				restored = false
+			}
+		}
+
+		if cw.Status == ChartWritten && restored {
+			cw.Status = ChartRolledBack
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+//=============================================================================
+
+func (f *txFile) commit() error {
+	previous, err := readFile(f.path...)
+	if err != nil && !isNotExist(err) {
+		return err
+	}
+
+	f.previous = previous
+	f.existed  = err == nil
+
+	if f.data == nil {
+		if !f.existed {
+			return nil
+		}
+		err = deleteFile(f.path...)
+	} else {
+		err = replaceFile(f.data, f.staged, f.path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	f.written = true
+	return nil
+}
+
+//=============================================================================
+//=== Files that did not exist before the transaction are removed
+
+func (f *txFile) rollback() error {
+	if !f.written {
+		return nil
+	}
+
+	var err error
+	if f.existed {
+		err = writeFile(f.previous, f.path...)
+	} else {
+		err = deleteFile(f.path...)
+	}
+
+	if err == nil {
+		f.written = false
+	}
+
+	return err
+}
+
+//=============================================================================
+
+func buildStagingPath(username string, id uint, tx string) []string {
+	return []string{ username, strconv.Itoa(int(id)), StagingDir, tx }
+}
+
+//=============================================================================
+
+func buildStagedPath(tx []string, path []string) []string {
+	return append(append(slices.Clone(tx), stagingFiles), path...)
+}
+
+//=============================================================================
+
+func deleteTree(path ...string) error {
+	p, err := buildPath(path...)
+	if err != nil {
+		return err
+	}
+
+	if !isChecksummed(path) {
+		err = store.DeleteTree(p)
+		if isNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	unlock := lockChecksums(path[0], path[1])
+	defer unlock()
+
+	err = store.DeleteTree(p)
+	if err != nil && !isNotExist(err) {
+		return err
+	}
+
+	return updateChecksums(path, removeChecksumTree)
+}
+
+//=============================================================================
+//=== Runs the rest of a journaled transaction: staged files that are gone
+//=== have already been renamed over the live ones
+
+func recoverTransaction(username string, id uint, name string) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	tx := buildStagingPath(username, id, name)
+
+	data, err := readFile(append(slices.Clone(tx), JournalFile)...)
+	if err != nil && !isNotExist(err) {
+		return err
+	}
+
+	if err == nil {
+		j := txJournal{}
+		err = json.Unmarshal(data, &j)
+		if err != nil {
+			return err
+		}
+
+		err = completeTransaction(username, id, tx, &j)
+		if err != nil {
+			return err
+		}
+	}
+
+	return deleteTree(tx...)
+}
+
+//=============================================================================
+
+func completeTransaction(username string, id uint, tx []string, j *txJournal) error {
+	for _, entry := range j.Files {
+		live := append([]string{ username, strconv.Itoa(int(id)) }, entry.Path...)
+
+		if entry.Delete {
+			err := deleteFile(live...)
+			if err != nil && !isNotExist(err) {
+				return err
+			}
+			continue
+		}
+
+		staged := buildStagedPath(tx, entry.Path)
+
+		data, err := readFile(staged...)
+		if err != nil {
+			if isNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		err = replaceFile(data, staged, live)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, chartType := range j.Types {
+		err := DeleteVariants(username, id, VariantEquityChart, chartType)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
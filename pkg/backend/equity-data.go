@@ -0,0 +1,99 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"strconv"
+	"strings"
+)
+
+//=============================================================================
+//=== Equity series used to render the charts, stored as JSON in the trading
+//=== system folder as <type>-equity-data.json
+//=============================================================================
+
+const EquityData = "equity-data.json"
+
+//=============================================================================
+
+func GetEquityDataTypes(username string, id uint) ([]string, error) {
+	files, err := getFiles(username, strconv.Itoa(int(id)))
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+
+	for _, file := range files {
+		if !file.IsDir && strings.HasSuffix(file.Name, "-"+ EquityData) {
+			types = append(types, strings.TrimSuffix(file.Name, "-"+ EquityData))
+		}
+	}
+
+	return types, nil
+}
+
+//=============================================================================
+
+func GetEquityDataInfo(username string, id uint, chartType string) (*FileInfo, error) {
+	return statFile(username, strconv.Itoa(int(id)), buildEquityDataName(chartType))
+}
+
+//=============================================================================
+
+func ReadEquityData(username string, id uint, chartType string) ([]byte, error) {
+	return readFile(username, strconv.Itoa(int(id)), buildEquityDataName(chartType))
+}
+
+//=============================================================================
+
+func WriteEquityData(username string, id uint, chartType string, data []byte) error {
+	err := writeFile(data, username, strconv.Itoa(int(id)), buildEquityDataName(chartType))
+	if err != nil {
+		return err
+	}
+
+	return DeleteVariants(username, id, VariantEquityData, chartType)
+}
+
+//=============================================================================
+
+func DeleteEquityData(username string, id uint, chartType string) error {
+	err := deleteFile(username, strconv.Itoa(int(id)), buildEquityDataName(chartType))
+	if err != nil {
+		return err
+	}
+
+	return DeleteVariants(username, id, VariantEquityData, chartType)
+}
+
+//=============================================================================
+
+func buildEquityDataName(chartType string) string {
+	return chartType +"-"+ EquityData
+}
+
+//=============================================================================
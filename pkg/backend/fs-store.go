@@ -0,0 +1,261 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"os"
+	"path/filepath"
+)
+
+//=============================================================================
+
+type FsStore struct {
+	root string
+}
+
+//=============================================================================
+
+func NewFsStore(root string) (*FsStore, error) {
+	err := os.MkdirAll(root, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FsStore{ root: root }, nil
+}
+
+//=============================================================================
+//===
+//=== Store interface
+//===
+//=============================================================================
+
+func (s *FsStore) Get(path string) ([]byte, error) {
+	file, err := s.abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(file)
+}
+
+//=============================================================================
+
+func (s *FsStore) Put(path string, data []byte) error {
+	file, err := s.abs(path)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(file +".temp", data, 0600)
+	if err != nil {
+		return err
+	}
+
+	_,err = os.Stat(file)
+
+	if err == nil {
+		err = os.Remove(file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(file +".temp", file)
+}
+
+//=============================================================================
+
+func (s *FsStore) List(path string) ([]FileInfo, error) {
+	dir, err := s.abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []FileInfo
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, *toFileInfo(info))
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+
+func (s *FsStore) Delete(path string) error {
+	file, err := s.abs(path)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(file)
+}
+
+//=============================================================================
+
+func (s *FsStore) Stat(path string) (*FileInfo, error) {
+	file, err := s.abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFileInfo(info), nil
+}
+
+//=============================================================================
+
+func (s *FsStore) DeleteTree(path string) error {
+	dir, err := s.abs(path)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+//=============================================================================
+//===
+//=== DirMaker interface
+//===
+//=============================================================================
+
+func (s *FsStore) MakeDir(path string) error {
+	dir, err := s.abs(path)
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(dir, 0700)
+}
+
+//=============================================================================
+//===
+//=== Renamer interface
+//===
+//=============================================================================
+
+func (s *FsStore) Rename(from string, to string) error {
+	src, err := s.abs(from)
+	if err != nil {
+		return err
+	}
+
+	dst, err := s.abs(to)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(src, dst)
+}
+
+//=============================================================================
+//===
+//=== Mover interface
+//===
+//=============================================================================
+
+func (s *FsStore) Move(from string, to string) error {
+	src, err := s.abs(from)
+	if err != nil {
+		return err
+	}
+
+	dst, err := s.abs(to)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(dst)
+	if err == nil {
+		return &os.PathError{ Op: "move", Path: to, Err: os.ErrExist }
+	}
+
+	err = os.MkdirAll(filepath.Dir(dst), 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(src, dst)
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+//=== Paths are checked by buildPath: this only guards against other callers
+
+func (s *FsStore) abs(path string) (string, error) {
+	local := filepath.FromSlash(path)
+	if path != "" && !filepath.IsLocal(local) {
+		return "", &InvalidPathError{ Segment: path, Reason: "outside the storage root" }
+	}
+
+	return filepath.Join(s.root, local), nil
+}
+
+//=============================================================================
+
+func toFileInfo(info os.FileInfo) *FileInfo {
+	return &FileInfo{
+		Name   : info.Name(),
+		Size   : info.Size(),
+		ModTime: info.ModTime(),
+		IsDir  : info.IsDir(),
+	}
+}
+
+//=============================================================================
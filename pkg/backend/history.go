@@ -0,0 +1,214 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+//=== Generic revision history. Each history lives in its own folder that
+//=== contains an index file plus one immutable file per revision.
+//=============================================================================
+
+const (
+	HistoryDir    = "history"
+	HistoryIndex  = "index.json"
+
+	AnyRevision   = -1
+	UnknownAuthor = "unknown"
+)
+
+//=============================================================================
+
+var ErrRevisionMismatch = errors.New("revision mismatch")
+var ErrTagExists        = errors.New("tag already exists")
+
+//=============================================================================
+
+func readRevisions(base ...string) ([]*Revision, error) {
+	data, err := readFile(append(base, HistoryIndex)...)
+	if err != nil {
+		if isNotExist(err) {
+			return []*Revision{}, nil
+		}
+		return nil, err
+	}
+
+	var list []*Revision
+	err = json.Unmarshal(data, &list)
+
+	return list, err
+}
+
+//=============================================================================
+
+func readRevision(rev int, base ...string) (*Revision, []byte, error) {
+	list, err := readRevisions(base...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, r := range list {
+		if r.Revision == rev {
+			var data []byte
+			data, err = readFile(append(base, revisionFile(rev))...)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return r, data, nil
+		}
+	}
+
+	return nil, nil, notExist("revision", strings.Join(base, "/") +"/"+ strconv.Itoa(rev))
+}
+
+//=============================================================================
+//=== Must be called with the trading system lock held
+
+func addRevision(data []byte, r *Revision, base ...string) error {
+	list, err := readRevisions(base...)
+	if err != nil {
+		return err
+	}
+
+	r.Revision  = 1
+	r.Timestamp = time.Now().UTC()
+	r.Size      = len(data)
+
+	if len(list) > 0 {
+		r.Revision = list[len(list) -1].Revision +1
+	}
+
+	err = writeFile(data, append(base, revisionFile(r.Revision))...)
+	if err != nil {
+		return err
+	}
+
+	return writeRevisions(append(list, r), base...)
+}
+
+//=============================================================================
+//=== Content stored before its history existed becomes the first revision,
+//=== so that the first save does not lose it. Its author is not known. Must
+//=== be called with the trading system lock held
+
+func addBaselineRevision(file []string, base ...string) error {
+	list, err := readRevisions(base...)
+	if err != nil || len(list) > 0 {
+		return err
+	}
+
+	data, err := readFile(file...)
+	if err != nil {
+		if isNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	info, err := statFile(file...)
+	if err != nil {
+		return err
+	}
+
+	r := &Revision{
+		Revision : 1,
+		Timestamp: info.ModTime.UTC(),
+		Author   : UnknownAuthor,
+		Size     : len(data),
+	}
+
+	err = writeFile(data, append(base, revisionFile(r.Revision))...)
+	if err != nil {
+		return err
+	}
+
+	return writeRevisions([]*Revision{ r }, base...)
+}
+
+//=============================================================================
+//=== Tags are unique inside a history. Must be called with the trading
+//=== system lock held
+
+func tagRevision(rev int, tag string, base ...string) (*Revision, error) {
+	list, err := readRevisions(base...)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *Revision
+
+	for _, r := range list {
+		if r.Revision == rev {
+			target = r
+		}
+
+		for _, t := range r.Tags {
+			if t == tag {
+				if r.Revision == rev {
+					return r, nil
+				}
+				return nil, ErrTagExists
+			}
+		}
+	}
+
+	if target == nil {
+		return nil, notExist("revision", strings.Join(base, "/") +"/"+ strconv.Itoa(rev))
+	}
+
+	target.Tags = append(target.Tags, tag)
+
+	return target, writeRevisions(list, base...)
+}
+
+//=============================================================================
+
+func writeRevisions(list []*Revision, base ...string) error {
+	index, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(index, append(base, HistoryIndex)...)
+}
+
+//=============================================================================
+
+func revisionFile(rev int) string {
+	return strconv.Itoa(rev) +".rev"
+}
+
+//=============================================================================
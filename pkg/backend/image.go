@@ -0,0 +1,86 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"strconv"
+)
+
+//=============================================================================
+//=== Image gallery, stored in the 'image' folder with a metadata sidecar
+//=============================================================================
+
+func GetImageFiles(username string, id uint) ([]FileInfo, error) {
+	return getFilesWithMetadata(username, id, Image)
+}
+
+//=============================================================================
+
+func GetImageInfo(username string, id uint, name string) (*FileInfo, error) {
+	return statFile(username, strconv.Itoa(int(id)), Image, name)
+}
+
+//=============================================================================
+
+func ReadImage(username string, id uint, name string) ([]byte, error) {
+	return readFile(username, strconv.Itoa(int(id)), Image, name)
+}
+
+//=============================================================================
+//=== Returns nil if the image has no metadata
+
+func ReadImageMetadata(username string, id uint, name string) (*ImageMetadata, error) {
+	im := ImageMetadata{}
+	found, err := readMetadata(username, id, Image, name, &im)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &im, nil
+}
+
+//=============================================================================
+
+func WriteImage(username string, id uint, name string, data []byte, im *ImageMetadata) error {
+	err := writeFileWithMetadata(username, id, Image, name, data, im)
+	if err != nil {
+		return err
+	}
+
+	return DeleteVariants(username, id, VariantImage, name)
+}
+
+//=============================================================================
+
+func DeleteImage(username string, id uint, name string) error {
+	err := deleteFileWithMetadata(username, id, Image, name)
+	if err != nil {
+		return err
+	}
+
+	return DeleteVariants(username, id, VariantImage, name)
+}
+
+//=============================================================================
@@ -0,0 +1,64 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"strconv"
+	"sync"
+)
+
+//=============================================================================
+//=== Serialises read-modify-write operations on the same trading system
+//=============================================================================
+
+var locks sync.Map
+
+//--- Held for the short time needed to write a file and update its checksum
+var checksumLocks sync.Map
+
+//=============================================================================
+
+func lockTradingSystem(username string, id uint) func() {
+	return lock(&locks, username +"/"+ strconv.Itoa(int(id)))
+}
+
+//=============================================================================
+
+func lockChecksums(username string, id string) func() {
+	return lock(&checksumLocks, username +"/"+ id)
+}
+
+//=============================================================================
+
+func lock(locks *sync.Map, key string) func() {
+	m, _ := locks.LoadOrStore(key, &sync.Mutex{})
+
+	mutex := m.(*sync.Mutex)
+	mutex.Lock()
+
+	return mutex.Unlock
+}
+
+//=============================================================================
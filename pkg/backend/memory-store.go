@@ -0,0 +1,353 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"io/fs"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//=============================================================================
+//=== Volatile driver, mainly used by unit and integration tests
+//=============================================================================
+
+type MemoryStore struct {
+	sync.RWMutex
+	files map[string]*memoryFile
+	dirs  map[string]time.Time
+}
+
+//=============================================================================
+
+type memoryFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+//=============================================================================
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		files: map[string]*memoryFile{},
+		dirs : map[string]time.Time{},
+	}
+}
+
+//=============================================================================
+//===
+//=== Store interface
+//===
+//=============================================================================
+
+func (s *MemoryStore) Get(path string) ([]byte, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	file, ok := s.files[path]
+	if !ok {
+		return nil, notExist("get", path)
+	}
+
+	return cloneBytes(file.data), nil
+}
+
+//=============================================================================
+
+func (s *MemoryStore) Put(path string, data []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.dirs[path]; ok {
+		return &fs.PathError{ Op: "put", Path: path, Err: fs.ErrExist }
+	}
+
+	s.files[path] = &memoryFile{
+		data   : cloneBytes(data),
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func (s *MemoryStore) List(path string) ([]FileInfo, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	prefix := dirPrefix(path)
+	seen   := map[string]bool{}
+
+	var list []FileInfo
+
+	for name, file := range s.files {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			if i := strings.Index(rest, "/"); i >= 0 {
+				seen[rest[:i]] = true
+			} else {
+				list = append(list, FileInfo{
+					Name   : rest,
+					Size   : int64(len(file.data)),
+					ModTime: file.modTime,
+				})
+			}
+		}
+	}
+
+	for name := range s.dirs {
+		if rest, ok := strings.CutPrefix(name, prefix); ok && rest != "" {
+			if i := strings.Index(rest, "/"); i >= 0 {
+				rest = rest[:i]
+			}
+			seen[rest] = true
+		}
+	}
+
+	for name := range seen {
+		list = append(list, FileInfo{
+			Name   : name,
+			ModTime: s.dirs[prefix + name],
+			IsDir  : true,
+		})
+	}
+
+	if len(list) == 0 {
+		if _, ok := s.dirs[path]; !ok {
+			return nil, notExist("list", path)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+//=============================================================================
+
+func (s *MemoryStore) Delete(path string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.files[path]; !ok {
+		return notExist("delete", path)
+	}
+
+	delete(s.files, path)
+	return nil
+}
+
+//=============================================================================
+
+func (s *MemoryStore) Stat(path string) (*FileInfo, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	if file, ok := s.files[path]; ok {
+		return &FileInfo{
+			Name   : baseName(path),
+			Size   : int64(len(file.data)),
+			ModTime: file.modTime,
+		}, nil
+	}
+
+	if s.isDir(path) {
+		return &FileInfo{
+			Name   : baseName(path),
+			ModTime: s.dirs[path],
+			IsDir  : true,
+		}, nil
+	}
+
+	return nil, notExist("stat", path)
+}
+
+//=============================================================================
+
+func (s *MemoryStore) DeleteTree(path string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	prefix := dirPrefix(path)
+
+	for name := range s.files {
+		if name == path || strings.HasPrefix(name, prefix) {
+			delete(s.files, name)
+		}
+	}
+
+	for name := range s.dirs {
+		if name == path || strings.HasPrefix(name, prefix) {
+			delete(s.dirs, name)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== DirMaker interface
+//===
+//=============================================================================
+
+func (s *MemoryStore) MakeDir(path string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.files[path]; ok {
+		return &fs.PathError{ Op: "mkdir", Path: path, Err: fs.ErrExist }
+	}
+
+	if _, ok := s.dirs[path]; !ok {
+		s.dirs[path] = time.Now()
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Renamer interface
+//===
+//=============================================================================
+
+func (s *MemoryStore) Rename(from string, to string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	file, ok := s.files[from]
+	if !ok {
+		return notExist("rename", from)
+	}
+
+	if s.isDir(to) {
+		return &fs.PathError{ Op: "rename", Path: to, Err: fs.ErrExist }
+	}
+
+	delete(s.files, from)
+	s.files[to] = &memoryFile{
+		data   : file.data,
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Mover interface
+//===
+//=============================================================================
+
+func (s *MemoryStore) Move(from string, to string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if !s.isDir(from) {
+		return notExist("move", from)
+	}
+
+	if _, ok := s.files[to]; ok || s.isDir(to) {
+		return &fs.PathError{ Op: "move", Path: to, Err: fs.ErrExist }
+	}
+
+	prefix := dirPrefix(from)
+	files  := map[string]*memoryFile{}
+	dirs   := map[string]time.Time{}
+
+	for name, file := range s.files {
+		if rest, ok := strings.CutPrefix(name, prefix); ok {
+			delete(s.files, name)
+			files[dirPrefix(to) + rest] = file
+		}
+	}
+
+	for name, modTime := range s.dirs {
+		if name == from {
+			delete(s.dirs, name)
+			dirs[to] = modTime
+		} else if rest, ok := strings.CutPrefix(name, prefix); ok {
+			delete(s.dirs, name)
+			dirs[dirPrefix(to) + rest] = modTime
+		}
+	}
+
+	maps.Copy(s.files, files)
+	maps.Copy(s.dirs,  dirs)
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private methods
+//===
+//=============================================================================
+
+func (s *MemoryStore) isDir(path string) bool {
+	if _, ok := s.dirs[path]; ok {
+		return true
+	}
+
+	prefix := dirPrefix(path)
+
+	for name := range s.files {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	for name := range s.dirs {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=============================================================================
+
+func notExist(op string, path string) error {
+	return &fs.PathError{ Op: op, Path: path, Err: fs.ErrNotExist }
+}
+
+//=============================================================================
+
+func cloneBytes(data []byte) []byte {
+	if data == nil {
+		return []byte{}
+	}
+
+	return append([]byte{}, data...)
+}
+
+//=============================================================================
@@ -24,6 +24,8 @@ THE SOFTWARE.
 
 package backend
 
+import "time"
+
 //=============================================================================
 
 type TradingSystem struct {
@@ -33,3 +35,91 @@ type TradingSystem struct {
 }
 
 //=============================================================================
+
+type Revision struct {
+	Revision     int       `json:"revision"`
+	Timestamp    time.Time `json:"timestamp"`
+	Author       string    `json:"author"`
+	Size         int       `json:"size"`
+	RestoredFrom int       `json:"restoredFrom,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+}
+
+//=============================================================================
+
+type Deployment struct {
+	Revision  int       `json:"revision"`
+	Timestamp time.Time `json:"timestamp"`
+	Author    string    `json:"author"`
+}
+
+//=============================================================================
+
+type ReportMetadata struct {
+	Title       string    `json:"title"`
+	PeriodFrom  string    `json:"periodFrom,omitempty"`
+	PeriodTo    string    `json:"periodTo,omitempty"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Tool        string    `json:"tool,omitempty"`
+	Author      string    `json:"author"`
+}
+
+//=============================================================================
+
+type ImageMetadata struct {
+	Format     string    `json:"format"`
+	Width      int       `json:"width"`
+	Height     int       `json:"height"`
+	UploadedAt time.Time `json:"uploadedAt"`
+	Author     string    `json:"author"`
+}
+
+//=============================================================================
+
+type EquityChartType struct {
+	Type    string   `json:"type"`
+	Formats []string `json:"formats"`
+}
+
+//=============================================================================
+
+type EquityChartFile struct {
+	FileInfo
+	Type   string
+	Format string
+}
+
+//=============================================================================
+//=== Sent by the producer of the chart. Author and UploadedAt are set by the
+//=== storage manager
+
+type EquityChartMetadata struct {
+	GeneratedAt     *time.Time `json:"generatedAt,omitempty"`
+	DataFrom        *time.Time `json:"dataFrom,omitempty"`
+	DataTo          *time.Time `json:"dataTo,omitempty"`
+	Trades          *int       `json:"trades,omitempty"`
+	ProducerVersion string     `json:"producerVersion,omitempty"`
+	UploadedAt      time.Time  `json:"uploadedAt"`
+	Author          string     `json:"author"`
+}
+
+//=============================================================================
+
+type ChartSnapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+	Format    string    `json:"format"`
+	Size      int64     `json:"size"`
+}
+
+//=============================================================================
+//=== Name is the one of the trading system when it was deleted
+
+type TrashEntry struct {
+	Entry     string    `json:"entry"`
+	Id        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+//=============================================================================
@@ -0,0 +1,138 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"errors"
+	"github.com/bit-fever/core/req"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//=============================================================================
+//=== Path safety layer. Usernames, chart types and file names come from
+//=== tokens, messages and requests: every path handed to a Store is built
+//=== from segments that are checked here, so that nothing can escape the
+//=== subtree of its user (<username>/...).
+//=============================================================================
+
+const MaxSegmentLength = 255
+
+//=============================================================================
+
+var ErrInvalidPath = errors.New("invalid path")
+
+//=============================================================================
+
+type InvalidPathError struct {
+	Segment string
+	Reason  string
+}
+
+//=============================================================================
+
+func (e *InvalidPathError) Error() string {
+	return "invalid path segment "+ strconv.Quote(e.Segment) +": "+ e.Reason
+}
+
+//=============================================================================
+
+func (e *InvalidPathError) Is(target error) bool {
+	return target == ErrInvalidPath
+}
+
+//=============================================================================
+//=== Lets req.ReturnError reply with a 400 wherever the error surfaces
+
+func (e *InvalidPathError) As(target any) bool {
+	if ae, ok := target.(*req.AppError); ok {
+		*ae = req.AppError{ Code: http.StatusBadRequest, Message: e.Error() }
+		return true
+	}
+
+	return false
+}
+
+//=============================================================================
+
+func CheckSegment(segment string) error {
+	reason := ""
+
+	switch {
+	case segment == "":
+		reason = "empty"
+	case len(segment) > MaxSegmentLength:
+		reason = "too long"
+	case segment == "." || segment == "..":
+		reason = "relative reference"
+	case strings.ContainsAny(segment, "/\\"):
+		reason = "contains a separator"
+	case !utf8.ValidString(segment):
+		reason = "invalid UTF-8"
+	case strings.IndexFunc(segment, isControl) >= 0:
+		reason = "contains a control character"
+	}
+
+	if reason != "" {
+		return &InvalidPathError{ Segment: segment, Reason: reason }
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func buildPath(segments ...string) (string, error) {
+	for _, segment := range segments {
+		if err := CheckSegment(segment); err != nil {
+			return "", err
+		}
+	}
+
+	p := strings.Join(segments, "/")
+
+	//--- Cannot happen with valid segments: a last line of defence
+	if len(segments) == 0 || path.Clean(p) != p || !strings.HasPrefix(p +"/", segments[0] +"/") {
+		return "", &InvalidPathError{ Segment: p, Reason: "outside the user's subtree" }
+	}
+
+	return p, nil
+}
+
+//=============================================================================
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+//=============================================================================
@@ -0,0 +1,186 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"errors"
+	"github.com/bit-fever/core/req"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+func TestCheckSegment(t *testing.T) {
+	valid := []string{ "john", "42", "daily-equity-chart.png", "report v1.pdf", "..hidden", "a..b", "équité" }
+
+	for _, segment := range valid {
+		if err := CheckSegment(segment); err != nil {
+			t.Errorf("CheckSegment(%q): unexpected error %v", segment, err)
+		}
+	}
+
+	invalid := []string{ "", ".", "..", "../x", "a/b", `a\b`, "/", "a\x00b", "a\nb", "\xff", strings.Repeat("a", MaxSegmentLength +1) }
+
+	for _, segment := range invalid {
+		if err := CheckSegment(segment); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("CheckSegment(%q): expected ErrInvalidPath, got %v", segment, err)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestBuildPath(t *testing.T) {
+	if p, err := buildPath("john", "1", "image", "chart.png"); err != nil || p != "john/1/image/chart.png" {
+		t.Errorf("buildPath: got %q, %v", p, err)
+	}
+
+	tests := [][]string{
+		{},
+		{ "..", "jane", "1" },
+		{ "john", "1", "../../jane" },
+		{ "john", "1", "../../x-equity-chart.png" },
+		{ "", "1", "info.json" },
+	}
+
+	for _, test := range tests {
+		if _, err := buildPath(test...); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("buildPath(%q): expected ErrInvalidPath, got %v", test, err)
+		}
+	}
+}
+
+//=============================================================================
+//=== The core library must see a 400 error
+
+func TestInvalidPathError_AppError(t *testing.T) {
+	_, err := readFile("john", "1", "../../etc", "passwd")
+
+	ae := req.AppError{}
+	if !errors.As(errors.Join(errors.New("wrapped"), err), &ae) || ae.Code != http.StatusBadRequest {
+		t.Errorf("InvalidPathError: expected a 400 AppError, got %+v from %v", ae, err)
+	}
+}
+
+//=============================================================================
+
+func TestFsStore_OutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewFsStore(filepath.Join(root, "storage"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{ "../secret", "john/../../secret", "/etc/passwd" } {
+		if err = s.Put(p, []byte("x")); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("FsStore.Put(%q): expected ErrInvalidPath, got %v", p, err)
+		}
+		if _, err = s.Get(p); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("FsStore.Get(%q): expected ErrInvalidPath, got %v", p, err)
+		}
+	}
+
+	if _, err = os.Stat(filepath.Join(root, "secret")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("FsStore: file written outside the root")
+	}
+}
+
+//=============================================================================
+
+func FuzzBuildPath(f *testing.F) {
+	seeds := [][3]string{
+		{ "john", "1", "daily-equity-chart.png" },
+		{ "..", "1", "x" },
+		{ "john", "1", "../../x" },
+		{ "john", "..", "x" },
+		{ "john/..", "1", "x" },
+		{ "john", "1", "a\\..\\..\\x" },
+		{ "john", "1", "\x00" },
+		{ ".", "", "x" },
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed[0], seed[1], seed[2])
+	}
+
+	f.Fuzz(func(t *testing.T, username string, id string, name string) {
+		p, err := buildPath(username, id, name)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidPath) {
+				t.Fatalf("buildPath: unexpected error type %v", err)
+			}
+			return
+		}
+
+		//--- A valid path stays in the user's subtree, on every platform
+		if p != path.Clean(p) || !strings.HasPrefix(p, username +"/") || strings.Count(p, "/") != 2 {
+			t.Fatalf("buildPath(%q, %q, %q): escaped to %q", username, id, name, p)
+		}
+		if !filepath.IsLocal(filepath.FromSlash(p)) || strings.Contains(p, "\\") {
+			t.Fatalf("buildPath(%q, %q, %q): not a local path %q", username, id, name, p)
+		}
+		for _, segment := range strings.Split(p, "/") {
+			if segment == ".." || segment == "." || segment == "" {
+				t.Fatalf("buildPath(%q, %q, %q): bad segment in %q", username, id, name, p)
+			}
+		}
+	})
+}
+
+//=============================================================================
+
+func FuzzMemoryStore_Isolation(f *testing.F) {
+	f.Add("../jane")
+	f.Add("x/../../jane")
+	f.Add("chart.png")
+
+	f.Fuzz(func(t *testing.T, name string) {
+		InitMemoryStorage(nil)
+
+		if err := writeFile([]byte("secret"), "jane", "1", "info.json"); err != nil {
+			t.Fatal(err)
+		}
+
+		//--- Whatever the name, john can never read or overwrite jane's files
+		if data, err := readFile("john", "1", name); err == nil && string(data) == "secret" {
+			t.Fatalf("readFile(%q): read another user's file", name)
+		}
+
+		_ = writeFile([]byte("evil"), "john", "1", name)
+
+		if data, _ := readFile("jane", "1", "info.json"); string(data) != "secret" {
+			t.Fatalf("writeFile(%q): overwrote another user's file", name)
+		}
+	})
+}
+
+//=============================================================================
@@ -0,0 +1,76 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"strconv"
+)
+
+//=============================================================================
+//=== Backtest reports, stored in the 'report' folder with a metadata sidecar
+//=============================================================================
+
+func GetReportFiles(username string, id uint) ([]FileInfo, error) {
+	return getFilesWithMetadata(username, id, Report)
+}
+
+//=============================================================================
+
+func GetReportInfo(username string, id uint, name string) (*FileInfo, error) {
+	return statFile(username, strconv.Itoa(int(id)), Report, name)
+}
+
+//=============================================================================
+
+func ReadReport(username string, id uint, name string) ([]byte, error) {
+	return readFile(username, strconv.Itoa(int(id)), Report, name)
+}
+
+//=============================================================================
+//=== Reports uploaded without metadata get an empty one
+
+func ReadReportMetadata(username string, id uint, name string) (*ReportMetadata, error) {
+	rm := ReportMetadata{}
+	_, err := readMetadata(username, id, Report, name, &rm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rm, nil
+}
+
+//=============================================================================
+
+func WriteReport(username string, id uint, name string, data []byte, rm *ReportMetadata) error {
+	return writeFileWithMetadata(username, id, Report, name, data, rm)
+}
+
+//=============================================================================
+
+func DeleteReport(username string, id uint, name string) error {
+	return deleteFileWithMetadata(username, id, Report, name)
+}
+
+//=============================================================================
@@ -0,0 +1,532 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+//=== Driver for S3 compatible object storages (AWS, MinIO, Ceph, ...).
+//=== Trading system folders are mapped to key prefixes and requests are
+//=== signed with AWS signature version 4.
+//=============================================================================
+
+type S3Store struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	client    *http.Client
+	now       func() time.Time
+}
+
+//=============================================================================
+
+func NewS3Store(cfg *app.Storage, client *http.Client) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("S3 driver: missing bucket")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3."+ cfg.Region +".amazonaws.com"
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Store{
+		endpoint : u,
+		bucket   : cfg.Bucket,
+		region   : region,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+		client   : client,
+		now      : time.Now,
+	}, nil
+}
+
+//=============================================================================
+//===
+//=== Store interface
+//===
+//=============================================================================
+
+func (s *S3Store) Get(path string) ([]byte, error) {
+	res, err := s.execute(http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, s.toError(res, "get", path)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+//=============================================================================
+
+func (s *S3Store) Put(path string, data []byte) error {
+	res, err := s.execute(http.MethodPut, path, nil, data)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return s.toError(res, "put", path)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func (s *S3Store) List(path string) ([]FileInfo, error) {
+	objects, prefixes, err := s.listObjects(dirPrefix(path), "/")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) == 0 && len(prefixes) == 0 {
+		return nil, notExist("list", path)
+	}
+
+	var list []FileInfo
+
+	for _, p := range prefixes {
+		list = append(list, FileInfo{
+			Name : baseName(p),
+			IsDir: true,
+		})
+	}
+
+	for _, o := range objects {
+		list = append(list, FileInfo{
+			Name   : baseName(o.Key),
+			Size   : o.Size,
+			ModTime: o.LastModified,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+//=============================================================================
+
+func (s *S3Store) Delete(path string) error {
+	_, err := s.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.execute(http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return s.toError(res, "delete", path)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func (s *S3Store) Stat(path string) (*FileInfo, error) {
+	res, err := s.execute(http.MethodHead, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		modTime, _ := http.ParseTime(res.Header.Get("Last-Modified"))
+
+		return &FileInfo{
+			Name   : baseName(path),
+			Size   : res.ContentLength,
+			ModTime: modTime,
+		}, nil
+	}
+
+	if res.StatusCode != http.StatusNotFound {
+		return nil, s.toError(res, "stat", path)
+	}
+
+	//--- Objects storages have no directories: check if the prefix exists
+
+	objects, prefixes, err := s.listObjects(dirPrefix(path), "/")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objects) == 0 && len(prefixes) == 0 {
+		return nil, notExist("stat", path)
+	}
+
+	return &FileInfo{
+		Name : baseName(path),
+		IsDir: true,
+	}, nil
+}
+
+//=============================================================================
+
+func (s *S3Store) DeleteTree(path string) error {
+	objects, _, err := s.listObjects(dirPrefix(path), "")
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objects {
+		res, err := s.execute(http.MethodDelete, o.Key, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		_ = res.Body.Close()
+
+		if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+			return s.toError(res, "delete", o.Key)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Object listing
+//===
+//=============================================================================
+
+type s3Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+//=============================================================================
+
+type s3ListResult struct {
+	Contents              []s3Object `xml:"Contents"`
+	CommonPrefixes        []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	NextContinuationToken string     `xml:"NextContinuationToken"`
+}
+
+//=============================================================================
+
+func (s *S3Store) listObjects(prefix string, delimiter string) ([]s3Object, []string, error) {
+	var objects  []s3Object
+	var prefixes []string
+
+	token := ""
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix",    prefix)
+
+		if delimiter != "" {
+			query.Set("delimiter", delimiter)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		res, err := s.execute(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if res.StatusCode != http.StatusOK {
+			err = s.toError(res, "list", prefix)
+			_ = res.Body.Close()
+			return nil, nil, err
+		}
+
+		result := s3ListResult{}
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		_ = res.Body.Close()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		objects = append(objects, result.Contents...)
+
+		for _, cp := range result.CommonPrefixes {
+			prefixes = append(prefixes, cp.Prefix)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, prefixes, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
+
+//=============================================================================
+//===
+//=== Request execution and signing
+//===
+//=============================================================================
+
+func (s *S3Store) execute(method string, key string, query url.Values, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+
+	if s.pathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") +"/"+ s.bucket +"/"+ key
+	} else {
+		u.Host = s.bucket +"."+ u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") +"/"+ key
+	}
+
+	if query != nil {
+		u.RawQuery = encodeQuery(query)
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if body == nil {
+		req.Body          = http.NoBody
+		req.ContentLength = 0
+	}
+
+	req.URL.RawPath = encodePath(req.URL.Path)
+	signRequest(req, body, s.accessKey, s.secretKey, s.region, s.now().UTC())
+
+	return s.client.Do(req)
+}
+
+//=============================================================================
+
+func (s *S3Store) toError(res *http.Response, op string, path string) error {
+	if res.StatusCode == http.StatusNotFound {
+		return notExist(op, path)
+	}
+
+	if res.StatusCode == http.StatusForbidden {
+		return &fs.PathError{ Op: op, Path: path, Err: fs.ErrPermission }
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("S3 driver: %s %s failed with status %d: %s", op, path, res.StatusCode, string(body))
+}
+
+//=============================================================================
+
+const s3Algorithm = "AWS4-HMAC-SHA256"
+
+//=============================================================================
+
+func signRequest(req *http.Request, body []byte, accessKey string, secretKey string, region string, now time.Time) {
+	amzDate  := now.Format("20060102T150405Z")
+	date     := now.Format("20060102")
+	sum      := sha256.Sum256(body)
+	bodyHash := hex.EncodeToString(sum[:])
+
+	req.Header.Set("X-Amz-Date",           amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", bodyHash)
+
+	//--- Canonical headers (host + every header set on the request)
+
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	var names []string
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonHeaders := ""
+	for _, name := range names {
+		canonHeaders += name +":"+ headers[name] +"\n"
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonRequest := strings.Join([]string{
+		req.Method,
+		encodePath(req.URL.Path),
+		encodeQuery(req.URL.Query()),
+		canonHeaders,
+		signedHeaders,
+		bodyHash,
+	}, "\n")
+
+	//--- String to sign
+
+	scope   := date +"/"+ region +"/s3/aws4_request"
+	crHash  := sha256.Sum256([]byte(canonRequest))
+	toSign  := s3Algorithm +"\n"+ amzDate +"\n"+ scope +"\n"+ hex.EncodeToString(crHash[:])
+
+	key := hmacSha256([]byte("AWS4"+ secretKey), date)
+	key  = hmacSha256(key, region)
+	key  = hmacSha256(key, "s3")
+	key  = hmacSha256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSha256(key, toSign))
+
+	req.Header.Set("Authorization", s3Algorithm +
+		" Credential="+ accessKey +"/"+ scope +
+		", SignedHeaders="+ signedHeaders +
+		", Signature="+ signature)
+}
+
+//=============================================================================
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+//=============================================================================
+
+func encodePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncode(seg)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+//=============================================================================
+
+func encodeQuery(query url.Values) string {
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k) +"="+ uriEncode(v))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+//=============================================================================
+//=== Encoding as required by SigV4: only unreserved characters are kept
+
+func uriEncode(s string) string {
+	var sb strings.Builder
+
+	for _, b := range []byte(s) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteString("%"+ strings.ToUpper(strconv.FormatInt(int64(b)|0x100, 16)[1:]))
+		}
+	}
+
+	return sb.String()
+}
+
+//=============================================================================
+
+func dirPrefix(p string) string {
+	if p == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(p, "/") +"/"
+}
+
+//=============================================================================
+
+func baseName(p string) string {
+	return path.Base(strings.TrimSuffix(p, "/"))
+}
+
+//=============================================================================
@@ -0,0 +1,243 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//=============================================================================
+//=== The scrub re-hashes every file of every trading system and compares it
+//=== with the checksum manifest. Files without a checksum (written before
+//=== manifests existed) are adopted, the other differences are reported.
+//=============================================================================
+
+const (
+	ScrubReportFile = "scrub-report.json"
+
+	ScrubMismatch = "mismatch"
+	ScrubMissing  = "missing"
+	ScrubError    = "error"
+)
+
+//=============================================================================
+
+type ScrubReport struct {
+	StartedAt      time.Time     `json:"startedAt"`
+	FinishedAt     time.Time     `json:"finishedAt"`
+	TradingSystems int           `json:"tradingSystems"`
+	Files          int           `json:"files"`
+	Adopted        int           `json:"adopted"`
+	Issues         []*ScrubIssue `json:"issues"`
+}
+
+//=============================================================================
+
+type ScrubIssue struct {
+	Username string `json:"username"`
+	Id       uint   `json:"id"`
+	Path     string `json:"path"`
+	Status   string `json:"status"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+//=============================================================================
+
+var lastScrub struct {
+	sync.Mutex
+	report *ScrubReport
+}
+
+//=============================================================================
+//=== Only errors listing users or trading systems stop the scrub: problems
+//=== with single files are reported as issues. The report is saved at the
+//=== root of the storage and is returned even if it cannot be saved.
+
+func Scrub() (*ScrubReport, error) {
+	report := &ScrubReport{
+		StartedAt: time.Now().UTC(),
+		Issues   : []*ScrubIssue{},
+	}
+
+	users, err := getUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, username := range users {
+		files, err := getFiles(username)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if file.IsDir && isTradingSystemDir(file.Name) {
+				err = scrubTradingSystem(report, username, file.Name)
+				if err != nil {
+					return nil, err
+				}
+
+				report.TradingSystems++
+			}
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+
+	lastScrub.Lock()
+	lastScrub.report = report
+	lastScrub.Unlock()
+
+	data, err := json.Marshal(report)
+	if err == nil {
+		err = writeFile(data, ScrubReportFile)
+	}
+
+	return report, err
+}
+
+//=============================================================================
+//=== Loads the report saved by the last scrub of a previous run, if any
+
+func LoadLastScrubReport() error {
+	data, err := readFile(ScrubReportFile)
+	if err != nil {
+		if isNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	report := &ScrubReport{}
+	err = json.Unmarshal(data, report)
+	if err != nil {
+		return err
+	}
+
+	lastScrub.Lock()
+	defer lastScrub.Unlock()
+
+	if lastScrub.report == nil {
+		lastScrub.report = report
+	}
+
+	return nil
+}
+
+//=============================================================================
+//=== Returns nil if no scrub has completed yet
+
+func GetLastScrubReport() *ScrubReport {
+	lastScrub.Lock()
+	defer lastScrub.Unlock()
+
+	return lastScrub.report
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//=== Writes are blocked while the folder is scrubbed, so that a file is never
+//=== compared with the checksum of a concurrent write
+
+func scrubTradingSystem(report *ScrubReport, username string, id string) error {
+	unlock := lockChecksums(username, id)
+	defer unlock()
+
+	checksums, err := readChecksums(username, id)
+	if err != nil {
+		return err
+	}
+
+	files, err := walkUserTree(nil, username, id)
+	if err != nil {
+		return err
+	}
+
+	tsId, _ := strconv.ParseUint(id, 10, 32)
+	issue   := func(rel string, status string) *ScrubIssue {
+		i := &ScrubIssue{ Username: username, Id: uint(tsId), Path: rel, Status: status }
+		report.Issues = append(report.Issues, i)
+		return i
+	}
+
+	adopted := false
+	found   := map[string]bool{}
+
+	for _, file := range files {
+		rel := strings.TrimPrefix(file, id +"/")
+		found[rel] = true
+		report.Files++
+
+		data, err := readFile(append([]string{ username, id }, strings.Split(rel, "/")...)...)
+		if err != nil {
+			issue(rel, ScrubError).Error = err.Error()
+			continue
+		}
+
+		actual   := newChecksum(data, time.Now())
+		expected := checksums[rel]
+
+		if expected == nil {
+			checksums[rel] = actual
+			adopted = true
+			report.Adopted++
+		} else if expected.SHA256 != actual.SHA256 || expected.Size != actual.Size {
+			i := issue(rel, ScrubMismatch)
+			i.Expected = expected.SHA256
+			i.Actual   = actual.SHA256
+		}
+	}
+
+	var missing []string
+	for rel := range checksums {
+		if !found[rel] {
+			missing = append(missing, rel)
+		}
+	}
+
+	sort.Strings(missing)
+	for _, rel := range missing {
+		issue(rel, ScrubMissing).Expected = checksums[rel].SHA256
+	}
+
+	if adopted {
+		return writeChecksums(username, id, checksums)
+	}
+
+	return nil
+}
+
+//=============================================================================
@@ -0,0 +1,111 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+//=============================================================================
+//=== Files with a metadata sidecar, named <file>.meta.json and stored in the
+//=== same folder
+//=============================================================================
+
+const MetadataSuffix = ".meta.json"
+
+//=============================================================================
+
+func getFilesWithMetadata(username string, id uint, dir string) ([]FileInfo, error) {
+	files, err := getDirFiles(username, id, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []FileInfo{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name, MetadataSuffix) {
+			list = append(list, file)
+		}
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+//=== Returns false if the sidecar does not exist
+
+func readMetadata(username string, id uint, dir string, name string, meta any) (bool, error) {
+	data, err := readFile(username, strconv.Itoa(int(id)), dir, name + MetadataSuffix)
+	if err != nil {
+		if isNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, meta)
+}
+
+//=============================================================================
+
+func writeFileWithMetadata(username string, id uint, dir string, name string, data []byte, meta any) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	metaData, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	err = writeFile(data, username, strconv.Itoa(int(id)), dir, name)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(metaData, username, strconv.Itoa(int(id)), dir, name + MetadataSuffix)
+}
+
+//=============================================================================
+
+func deleteFileWithMetadata(username string, id uint, dir string, name string) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	err := deleteFile(username, strconv.Itoa(int(id)), dir, name)
+	if err != nil {
+		return err
+	}
+
+	err = deleteFile(username, strconv.Itoa(int(id)), dir, name + MetadataSuffix)
+	if isNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+//=============================================================================
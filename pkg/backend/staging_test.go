@@ -0,0 +1,163 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+
+package backend
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+//=============================================================================
+//=== Run against every driver: S3 has no rename primitive and writes again
+
+func TestStaging_Drivers(t *testing.T) {
+	drivers := map[string]func(t *testing.T) Store{
+		DriverFilesystem: func(t *testing.T) Store {
+			s, err := NewFsStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+		DriverMemory: func(t *testing.T) Store {
+			return NewMemoryStore()
+		},
+		DriverS3: func(t *testing.T) Store {
+			_, s := newFakeS3(t, "bucket")
+			return s
+		},
+	}
+
+	for name, factory := range drivers {
+		t.Run(name, func(t *testing.T) {
+			store = factory(t)
+			testRename(t)
+			testRecoverStaging(t)
+		})
+	}
+}
+
+//=============================================================================
+
+func testRename(t *testing.T) {
+	r, ok := store.(Renamer)
+	if !ok {
+		return
+	}
+
+	if err := store.Put("john/1/staged.png", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("john/1/live.png", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Rename("john/1/staged.png", "john/1/live.png"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if data, err := store.Get("john/1/live.png"); err != nil || string(data) != "new" {
+		t.Errorf("Rename: bad destination %q, %v", data, err)
+	}
+	if _, err := store.Get("john/1/staged.png"); !isNotExist(err) {
+		t.Errorf("Rename: source still there, %v", err)
+	}
+	if err := r.Rename("john/1/staged.png", "john/1/live.png"); !isNotExist(err) {
+		t.Errorf("Rename (missing): expected ErrNotExist, got %v", err)
+	}
+
+	if err := store.DeleteTree("john"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//=============================================================================
+
+func testRecoverStaging(t *testing.T) {
+	if err := AddTradingSystem(&TradingSystem{ Id: 1, Username: "john", Name: "Breakout" }); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteEquityChart("john", 1, []byte("old png"), "main", "png", nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile([]byte("old svg"), "john", "1", buildEquityChartName("main", "svg")); err != nil {
+		t.Fatal(err)
+	}
+
+	//--- A transaction that reached its journal is completed
+
+	committed := buildStagingPath("john", 1, "committed")
+	journal   := &txJournal{
+		Types: []string{ "main" },
+		Files: []*txEntry{
+			{ Path: []string{ buildEquityChartName("main", "png") } },
+			{ Path: []string{ buildEquityChartName("main", "svg") }, Delete: true },
+		},
+	}
+
+	data, _ := json.Marshal(journal)
+	if err := writeFile(data, append(committed, JournalFile)...); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile([]byte("new png"), buildStagedPath(committed, journal.Files[0].Path)...); err != nil {
+		t.Fatal(err)
+	}
+
+	//--- A transaction without a journal is discarded
+
+	aborted := buildStagingPath("john", 1, "aborted")
+	if err := writeFile([]byte("other png"), buildStagedPath(aborted, []string{ buildEquityChartName("other", "png") })...); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := RecoverStaging(); err != nil || count != 2 {
+		t.Fatalf("RecoverStaging: expected 2 removed areas, got %d, %v", count, err)
+	}
+
+	if data, err := ReadEquityChart("john", 1, "main", "png"); err != nil || string(data) != "new png" {
+		t.Errorf("RecoverStaging: journaled chart not committed, got %q, %v", data, err)
+	}
+	if _, err := ReadEquityChart("john", 1, "main", "svg"); !isNotExist(err) {
+		t.Errorf("RecoverStaging: journaled delete not run, %v", err)
+	}
+	if _, err := ReadEquityChart("john", 1, "other", "png"); !isNotExist(err) {
+		t.Errorf("RecoverStaging: chart without a journal committed, %v", err)
+	}
+	if files, err := getFiles("john", "1", StagingDir); err == nil && len(files) != 0 {
+		t.Errorf("RecoverStaging: staging area not cleaned, got %v", files)
+	}
+
+	sums, err := GetChecksums("john", 1)
+	if err != nil || sums[buildEquityChartName("main", "png")].Size != int64(len("new png")) {
+		t.Errorf("RecoverStaging: checksum not updated, got %v, %v", sums, err)
+	}
+
+	if count, err := RecoverStaging(); err != nil || count != 0 {
+		t.Errorf("RecoverStaging (clean): got %d, %v", count, err)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,110 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"errors"
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"io/fs"
+	"time"
+)
+
+//=============================================================================
+//=== Store is the abstraction over the physical storage. Paths are always
+//=== relative to the storage root and use '/' as separator, whatever the
+//=== underlying driver.
+//=============================================================================
+
+type Store interface {
+	Get       (path string) ([]byte, error)
+	Put       (path string, data []byte) error
+	List      (path string) ([]FileInfo, error)
+	Delete    (path string) error
+	Stat      (path string) (*FileInfo, error)
+	DeleteTree(path string) error
+}
+
+//=============================================================================
+//=== Optional interface for drivers that have a real notion of directory
+
+type DirMaker interface {
+	MakeDir(path string) error
+}
+
+//=============================================================================
+//=== Optional interface for drivers that can move a whole tree at once.
+//=== The destination must not exist.
+
+type Mover interface {
+	Move(from string, to string) error
+}
+
+//=============================================================================
+//=== Optional interface for drivers that can replace a file with another one
+//=== in a single step. The destination may exist.
+
+type Renamer interface {
+	Rename(from string, to string) error
+}
+
+//=============================================================================
+
+type FileInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	IsDir   bool      `json:"isDir"`
+}
+
+//=============================================================================
+
+const (
+	DriverFilesystem = "filesystem"
+	DriverS3         = "s3"
+	DriverMemory     = "memory"
+)
+
+//=============================================================================
+
+func newStore(cfg *app.Storage) (Store, error) {
+	switch cfg.Driver {
+	case "", DriverFilesystem:
+		return NewFsStore(cfg.Folder)
+	case DriverS3:
+		return NewS3Store(cfg, nil)
+	case DriverMemory:
+		return NewMemoryStore(), nil
+	}
+
+	return nil, errors.New("Unknown storage driver: "+ cfg.Driver)
+}
+
+//=============================================================================
+
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+//=============================================================================
@@ -0,0 +1,149 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package backend
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+//=== Every driver must behave the same way: this test is run against all of
+//=== them.
+//=============================================================================
+
+func TestStore_Drivers(t *testing.T) {
+	drivers := map[string]func(t *testing.T) Store{
+		DriverFilesystem: func(t *testing.T) Store {
+			s, err := NewFsStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+		DriverMemory: func(t *testing.T) Store {
+			return NewMemoryStore()
+		},
+		DriverS3: func(t *testing.T) Store {
+			_, s := newFakeS3(t, "bucket")
+			return s
+		},
+	}
+
+	for name, factory := range drivers {
+		t.Run(name, func(t *testing.T) {
+			testStore(t, factory(t))
+		})
+	}
+}
+
+//=============================================================================
+
+func testStore(t *testing.T, s Store) {
+	//--- Put & Get
+
+	if err := s.Put("john/1/documentation.txt", []byte("doc")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := s.Put("john/1/documentation.txt", []byte("new doc")); err != nil {
+		t.Fatalf("Put (overwrite): %v", err)
+	}
+	if err := s.Put("john/1/code/main.el", []byte("code")); err != nil {
+		t.Fatalf("Put (nested): %v", err)
+	}
+
+	data, err := s.Get("john/1/documentation.txt")
+	if err != nil || string(data) != "new doc" {
+		t.Fatalf("Get: got %q, %v", data, err)
+	}
+
+	//--- Stat
+
+	info, err := s.Stat("john/1/documentation.txt")
+	if err != nil || info.Size != 7 || info.IsDir {
+		t.Fatalf("Stat: got %+v, %v", info, err)
+	}
+
+	info, err = s.Stat("john/1/code")
+	if err != nil || !info.IsDir {
+		t.Fatalf("Stat (dir): got %+v, %v", info, err)
+	}
+
+	//--- List
+
+	list, err := s.List("john/1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	var names []string
+	for _, fi := range list {
+		names = append(names, fi.Name)
+	}
+
+	if strings.Join(names, ",") != "code,documentation.txt" {
+		t.Errorf("List: got %v", names)
+	}
+
+	//--- Missing files
+
+	if _, err = s.Get("john/1/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Get (missing): expected ErrNotExist, got %v", err)
+	}
+	if _, err = s.Stat("john/1/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat (missing): expected ErrNotExist, got %v", err)
+	}
+	if err = s.Delete("john/1/missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Delete (missing): expected ErrNotExist, got %v", err)
+	}
+	if _, err = s.List("john/2"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("List (missing): expected ErrNotExist, got %v", err)
+	}
+
+	//--- Delete
+
+	if err = s.Delete("john/1/documentation.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err = s.Get("john/1/documentation.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Get after Delete: expected ErrNotExist, got %v", err)
+	}
+
+	//--- DeleteTree
+
+	if err = s.DeleteTree("john/1"); err != nil {
+		t.Fatalf("DeleteTree: %v", err)
+	}
+	if _, err = s.Stat("john/1/code/main.el"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat after DeleteTree: expected ErrNotExist, got %v", err)
+	}
+	if err = s.DeleteTree("john/1"); err != nil {
+		t.Errorf("DeleteTree (missing): expected no error, got %v", err)
+	}
+}
+
+//=============================================================================
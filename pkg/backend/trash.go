@@ -0,0 +1,280 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+//=== Deleted trading systems are moved to <username>/trash/<id>-<timestamp>
+//=== and can be restored until they are purged
+//=============================================================================
+
+const TrashDir = "trash"
+
+//=============================================================================
+
+var ErrTradingSystemExists = errors.New("trading system already exists")
+
+//=============================================================================
+//=== An empty username returns the trash of all users
+
+func GetTrashEntries(username string) ([]*TrashEntry, error) {
+	users := []string{ username }
+
+	if username == "" {
+		var err error
+		users, err = getUsers()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	list := []*TrashEntry{}
+
+	for _, user := range users {
+		files, err := getFiles(user, TrashDir)
+		if err != nil {
+			if isNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, file := range files {
+			if te, ok := parseTrashEntryName(user, file.Name); ok && file.IsDir {
+				te.Name = readTrashedName(user, file.Name)
+				list = append(list, te)
+			}
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].DeletedAt.After(list[j].DeletedAt)
+	})
+
+	return list, nil
+}
+
+//=============================================================================
+
+func RestoreTradingSystem(username string, entry string) (*TrashEntry, error) {
+	te, ok := parseTrashEntryName(username, entry)
+	if !ok {
+		return nil, notExist("restore", buildTrashPath(username, entry))
+	}
+
+	unlock := lockTradingSystem(username, te.Id)
+	defer unlock()
+
+	_, err := statFile(username, TrashDir, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = GetTradingSystemInfo(username, te.Id)
+	if err == nil {
+		return nil, ErrTradingSystemExists
+	}
+	if !isNotExist(err) {
+		return nil, err
+	}
+
+	te.Name = readTrashedName(username, entry)
+
+	return te, moveTree([]string{ username, TrashDir, entry }, []string{ username, strconv.Itoa(int(te.Id)) })
+}
+
+//=============================================================================
+//=== Returns the number of purged entries
+
+func PurgeTrash(deletedBefore time.Time) (int, error) {
+	list, err := GetTrashEntries("")
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, te := range list {
+		if te.DeletedAt.Before(deletedBefore) {
+			err = deleteTree(te.Username, TrashDir, te.Entry)
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func moveTree(from []string, to []string) error {
+	src, err := buildPath(from...)
+	if err != nil {
+		return err
+	}
+
+	dst, err := buildPath(to...)
+	if err != nil {
+		return err
+	}
+
+	if mover, ok := store.(Mover); ok {
+		return mover.Move(src, dst)
+	}
+
+	//--- Drivers without a move primitive: copy, then delete the source
+
+	err = copyTree(src, dst)
+	if err != nil {
+		_ = store.DeleteTree(dst)
+		return err
+	}
+
+	return store.DeleteTree(src)
+}
+
+//=============================================================================
+
+func copyTree(src string, dst string) error {
+	files, err := store.List(src)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		from := src +"/"+ file.Name
+		to   := dst +"/"+ file.Name
+
+		if file.IsDir {
+			err = copyTree(from, to)
+		} else {
+			var data []byte
+			data, err = store.Get(from)
+			if err == nil {
+				err = store.Put(to, data)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//=== The root of the storage holds one folder per user
+
+func getUsers() ([]string, error) {
+	files, err := store.List("")
+	if err != nil {
+		if isNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var users []string
+	for _, file := range files {
+		if file.IsDir && CheckSegment(file.Name) == nil {
+			users = append(users, file.Name)
+		}
+	}
+
+	return users, nil
+}
+
+//=============================================================================
+//=== The name comes from info.json and is only informative
+
+func readTrashedName(username string, entry string) string {
+	data, err := readFile(username, TrashDir, entry, InfoFile)
+	if err != nil {
+		return ""
+	}
+
+	ts := TradingSystem{}
+	if json.Unmarshal(data, &ts) != nil {
+		return ""
+	}
+
+	return ts.Name
+}
+
+//=============================================================================
+
+func buildTrashEntryName(id uint, deletedAt time.Time) string {
+	return strconv.Itoa(int(id)) +"-"+ deletedAt.UTC().Format(snapshotLayout)
+}
+
+//=============================================================================
+
+func buildTrashPath(username string, entry string) string {
+	return strings.Join([]string{ username, TrashDir, entry }, "/")
+}
+
+//=============================================================================
+
+func parseTrashEntryName(username string, entry string) (*TrashEntry, bool) {
+	idPart, stamp, found := strings.Cut(entry, "-")
+	if !found {
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(idPart, 10, 32)
+	if err != nil {
+		return nil, false
+	}
+
+	deletedAt, err := time.Parse(snapshotLayout, stamp)
+	if err != nil {
+		return nil, false
+	}
+
+	return &TrashEntry{
+		Entry    : entry,
+		Id       : uint(id),
+		Username : username,
+		DeletedAt: deletedAt,
+	}, true
+}
+
+//=============================================================================
@@ -0,0 +1,133 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+//=============================================================================
+//=== Run against every driver: S3 has no move primitive and copies the tree
+
+func TestTrash_Drivers(t *testing.T) {
+	drivers := map[string]func(t *testing.T) Store{
+		DriverFilesystem: func(t *testing.T) Store {
+			s, err := NewFsStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+		DriverMemory: func(t *testing.T) Store {
+			return NewMemoryStore()
+		},
+		DriverS3: func(t *testing.T) Store {
+			_, s := newFakeS3(t, "bucket")
+			return s
+		},
+	}
+
+	for name, factory := range drivers {
+		t.Run(name, func(t *testing.T) {
+			store = factory(t)
+			testTrash(t)
+		})
+	}
+}
+
+//=============================================================================
+
+func testTrash(t *testing.T) {
+	ts := &TradingSystem{ Id: 7, Username: "john", Name: "Breakout" }
+
+	if err := AddTradingSystem(ts); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetTradingSystemDoc("john", 7, "Trend following"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteTradingSystem(7, "john"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetTradingSystemInfo("john", 7); !isNotExist(err) {
+		t.Fatalf("DeleteTradingSystem: trading system still there, %v", err)
+	}
+
+	//--- Deleting twice is not an error
+	if err := DeleteTradingSystem(7, "john"); err != nil {
+		t.Errorf("DeleteTradingSystem (missing): unexpected error %v", err)
+	}
+
+	list, err := GetTrashEntries("john")
+	if err != nil || len(list) != 1 || list[0].Id != 7 || list[0].Name != "Breakout" || list[0].DeletedAt.IsZero() {
+		t.Fatalf("GetTrashEntries: got %v, %v", list, err)
+	}
+
+	te, err := RestoreTradingSystem("john", list[0].Entry)
+	if err != nil || te.Id != 7 {
+		t.Fatalf("RestoreTradingSystem: got %+v, %v", te, err)
+	}
+	if doc, err := GetTradingSystemDoc("john", 7); err != nil || doc != "Trend following" {
+		t.Errorf("RestoreTradingSystem: bad documentation %q, %v", doc, err)
+	}
+	if list, _ = GetTrashEntries("john"); len(list) != 0 {
+		t.Errorf("RestoreTradingSystem: entry still in the trash %v", list)
+	}
+
+	//--- A trading system with the same id blocks the restore
+
+	if err = DeleteTradingSystem(7, "john"); err != nil {
+		t.Fatal(err)
+	}
+	if err = AddTradingSystem(ts); err != nil {
+		t.Fatal(err)
+	}
+
+	list, _ = GetTrashEntries("")
+	if _, err = RestoreTradingSystem("john", list[0].Entry); !errors.Is(err, ErrTradingSystemExists) {
+		t.Errorf("RestoreTradingSystem: expected ErrTradingSystemExists, got %v", err)
+	}
+	if _, err = RestoreTradingSystem("john", "7-yesterday"); !isNotExist(err) {
+		t.Errorf("RestoreTradingSystem (bad entry): expected ErrNotExist, got %v", err)
+	}
+
+	//--- Purge
+
+	if count, err := PurgeTrash(time.Now().Add(-time.Hour)); err != nil || count != 0 {
+		t.Errorf("PurgeTrash: recent entries purged (%d, %v)", count, err)
+	}
+	if count, err := PurgeTrash(time.Now().Add(time.Hour)); err != nil || count != 1 {
+		t.Errorf("PurgeTrash: expected 1 purged entry, got %d, %v", count, err)
+	}
+	if _, err = GetTradingSystemInfo("john", 7); err != nil {
+		t.Errorf("PurgeTrash: live trading system removed, %v", err)
+	}
+}
+
+//=============================================================================
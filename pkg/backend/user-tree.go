@@ -0,0 +1,160 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//=============================================================================
+//=== Whole tree of a user, used by backups. Paths are relative to the user
+//=== folder (<id>/<file>) and only trading system folders are included:
+//=== the trash, caches, staging areas and checksum manifests are skipped.
+//=============================================================================
+
+func GetUserFiles(username string) ([]string, error) {
+	files, err := getFiles(username)
+	if err != nil {
+		if isNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	list := []string{}
+
+	for _, file := range files {
+		if !file.IsDir || !isTradingSystemDir(file.Name) {
+			continue
+		}
+
+		list, err = walkUserTree(list, username, file.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+
+func ReadUserFile(username string, relPath string) ([]byte, error) {
+	return readFile(append([]string{ username }, strings.Split(relPath, "/")...)...)
+}
+
+//=============================================================================
+
+func WriteUserFile(username string, relPath string, data []byte) error {
+	return writeFile(data, append([]string{ username }, strings.Split(relPath, "/")...)...)
+}
+
+//=============================================================================
+//=== Creates a trading system from its files, whose paths are relative to its
+//=== folder. The trading system lock is held for the whole write, so no other
+//=== save can interleave, and info.json is written last.
+
+func CreateTradingSystemFiles(username string, id uint, files map[string][]byte) error {
+	unlock := lockTradingSystem(username, id)
+	defer unlock()
+
+	dir := strconv.Itoa(int(id))
+
+	_, err := statFile(username, dir, InfoFile)
+	if err == nil {
+		return ErrTradingSystemExists
+	}
+	if !isNotExist(err) {
+		return err
+	}
+
+	var paths []string
+	for relPath := range files {
+		if relPath != InfoFile {
+			paths = append(paths, relPath)
+		}
+	}
+
+	sort.Strings(paths)
+
+	if _, ok := files[InfoFile]; ok {
+		paths = append(paths, InfoFile)
+	}
+
+	for _, relPath := range paths {
+		err = writeFile(files[relPath], append([]string{ username, dir }, strings.Split(relPath, "/")...)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func walkUserTree(list []string, username string, dir string) ([]string, error) {
+	files, err := getFiles(append([]string{ username }, strings.Split(dir, "/")...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		rel := dir +"/"+ file.Name
+
+		//--- Top level entries of a trading system that can be rebuilt
+		if !strings.Contains(dir, "/") && !isChecksummed([]string{ username, dir, file.Name }) {
+			continue
+		}
+
+		if !file.IsDir {
+			list = append(list, rel)
+			continue
+		}
+
+		list, err = walkUserTree(list, username, rel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return list, nil
+}
+
+//=============================================================================
+
+func isTradingSystemDir(name string) bool {
+	_, err := strconv.ParseUint(name, 10, 32)
+	return err == nil
+}
+
+//=============================================================================
@@ -0,0 +1,71 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backend
+
+import (
+	"strconv"
+)
+
+//=============================================================================
+//=== Cache of generated variants (e.g. thumbnails). Variants of a source
+//=== live in cache/<group>/<source>/ and are named after the source hash, so
+//=== a stale variant is never returned. The folder is removed when the
+//=== source changes, to reclaim space.
+//=============================================================================
+
+const (
+	CacheDir = "cache"
+
+	VariantEquityChart = "equity-chart"
+	VariantEquityData  = "equity-data"
+	VariantImage       = Image
+)
+
+//=============================================================================
+
+func ReadVariant(username string, id uint, group string, source string, key string) ([]byte, error) {
+	return readFile(append(buildVariantPath(username, id, group, source), key)...)
+}
+
+//=============================================================================
+
+func WriteVariant(username string, id uint, group string, source string, key string, data []byte) error {
+	return writeFile(data, append(buildVariantPath(username, id, group, source), key)...)
+}
+
+//=============================================================================
+
+func DeleteVariants(username string, id uint, group string, source string) error {
+	return deleteTree(buildVariantPath(username, id, group, source)...)
+}
+
+//=============================================================================
+
+func buildVariantPath(username string, id uint, group string, source string) []string {
+	return []string{ username, strconv.Itoa(int(id)), CacheDir, group, source }
+}
+
+//=============================================================================
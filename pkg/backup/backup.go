@@ -0,0 +1,289 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+//=== Backup of the whole tree of a user as a tar.gz or zip archive. Paths
+//=== are relative to the user folder (<id>/<file>), so that an archive can
+//=== be restored under another user, and the manifest is the last entry.
+//=============================================================================
+
+const (
+	FormatTarGz = "tar.gz"
+	FormatZip   = "zip"
+
+	ManifestFile    = "manifest.json"
+	ManifestVersion = 1
+
+	DefaultMaxSize = 1024 * 1024 * 1024
+)
+
+var Formats = []string{ FormatTarGz, FormatZip }
+
+//=============================================================================
+
+var (
+	ErrInvalidFormat  = errors.New("invalid archive format")
+	ErrInvalidArchive = errors.New("invalid archive")
+	ErrInvalidRemap   = errors.New("invalid id remapping")
+	ErrConflict       = errors.New("trading system already exists")
+	ErrTooLarge       = errors.New("archive too large")
+)
+
+//=============================================================================
+
+type Manifest struct {
+	Version        int       `json:"version"`
+	Username       string    `json:"username"`
+	CreatedAt      time.Time `json:"createdAt"`
+	TradingSystems []uint    `json:"tradingSystems"`
+	Files          []*File   `json:"files"`
+}
+
+//=============================================================================
+
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+//=============================================================================
+
+func CheckFormat(format string) error {
+	if !slices.Contains(Formats, format) {
+		return fmt.Errorf("%w: %v (allowed are %v)", ErrInvalidFormat, format, strings.Join(Formats, ", "))
+	}
+
+	return nil
+}
+
+//=============================================================================
+//=== Maximum size of an archive to restore, uncompressed
+
+func GetMaxSize(cfg *app.Storage) int64 {
+	if cfg.MaxBackupSize > 0 {
+		return cfg.MaxBackupSize
+	}
+
+	return DefaultMaxSize
+}
+
+//=============================================================================
+
+func GetContentType(format string) string {
+	if format == FormatZip {
+		return "application/zip"
+	}
+
+	return "application/gzip"
+}
+
+//=============================================================================
+
+func Export(w io.Writer, username string, format string) (*Manifest, error) {
+	err := CheckFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := backend.GetUserFiles(username)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version       : ManifestVersion,
+		Username      : username,
+		CreatedAt     : time.Now().UTC(),
+		TradingSystems: []uint{},
+		Files         : []*File{},
+	}
+
+	aw := newArchiveWriter(w, format)
+
+	for _, path := range paths {
+		data, err := backend.ReadUserFile(username, path)
+		if err != nil {
+			return nil, err
+		}
+
+		err = aw.add(path, m.CreatedAt, data)
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		m.Files = append(m.Files, &File{
+			Path  : path,
+			Size  : int64(len(data)),
+			SHA256: hex.EncodeToString(sum[:]),
+		})
+
+		if id, _ := getTradingSystemId(path); !slices.Contains(m.TradingSystems, id) {
+			m.TradingSystems = append(m.TradingSystems, id)
+		}
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err == nil {
+		err = aw.add(ManifestFile, m.CreatedAt, data)
+	}
+	if err == nil {
+		err = aw.close()
+	}
+
+	return m, err
+}
+
+//=============================================================================
+//===
+//=== Archive writers
+//===
+//=============================================================================
+
+type archiveWriter interface {
+	add(name string, modTime time.Time, data []byte) error
+	close() error
+}
+
+//=============================================================================
+
+func newArchiveWriter(w io.Writer, format string) archiveWriter {
+	if format == FormatZip {
+		return &zipWriter{ zw: zip.NewWriter(w) }
+	}
+
+	gw := gzip.NewWriter(w)
+	return &tarWriter{ gw: gw, tw: tar.NewWriter(gw) }
+}
+
+//=============================================================================
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+//=============================================================================
+
+func (z *zipWriter) add(name string, modTime time.Time, data []byte) error {
+	w, err := z.zw.CreateHeader(&zip.FileHeader{ Name: name, Method: zip.Deflate, Modified: modTime })
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+//=============================================================================
+
+func (z *zipWriter) close() error {
+	return z.zw.Close()
+}
+
+//=============================================================================
+
+type tarWriter struct {
+	gw *gzip.Writer
+	tw *tar.Writer
+}
+
+//=============================================================================
+
+func (t *tarWriter) add(name string, modTime time.Time, data []byte) error {
+	err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name    : name,
+		Size    : int64(len(data)),
+		Mode    : 0600,
+		ModTime : modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = t.tw.Write(data)
+	return err
+}
+
+//=============================================================================
+
+func (t *tarWriter) close() error {
+	err := t.tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return t.gw.Close()
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+//=== Archive paths are <id>/<file>: every segment must be a safe path segment
+
+func getTradingSystemId(path string) (uint, error) {
+	segments := strings.Split(path, "/")
+	if len(segments) < 2 {
+		return 0, fmt.Errorf("%w: file outside a trading system: %v", ErrInvalidArchive, path)
+	}
+
+	for _, segment := range segments {
+		if err := backend.CheckSegment(segment); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+	}
+
+	id, err := strconv.ParseUint(segments[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: bad trading system id in %v", ErrInvalidArchive, path)
+	}
+
+	return uint(id), nil
+}
+
+//=============================================================================
@@ -0,0 +1,268 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+const limit = 1024 * 1024
+
+//=============================================================================
+
+func setup(t *testing.T) {
+	backend.InitMemoryStorage([]byte("default"))
+
+	for _, ts := range []*backend.TradingSystem{
+		{ Id: 7, Username: "john", Name: "Breakout" },
+		{ Id: 8, Username: "john", Name: "Mean reversion" },
+	} {
+		if err := backend.AddTradingSystem(ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := backend.SetTradingSystemDoc("john", 7, "Trend following"); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.WriteEquityChart("john", 7, []byte("chart"), "daily", backend.ChartFormatPNG, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.WriteUserFile("john", "8/code/main.el", []byte("buy next bar")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+//=============================================================================
+
+func export(t *testing.T, format string) []byte {
+	buf := &bytes.Buffer{}
+
+	m, err := Export(buf, "john", format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.TradingSystems) != 2 || len(m.Files) != 6 {
+		t.Fatalf("Export: bad manifest %+v", m)
+	}
+
+	return buf.Bytes()
+}
+
+//=============================================================================
+
+func TestBackup_RoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		setup(t)
+		data := export(t, format)
+
+		//--- Restore under another user
+		res, err := Import(data, format, "mary", map[uint]uint{ 7: 107 }, limit)
+		if err != nil {
+			t.Fatalf("%s: Import: unexpected error %v", format, err)
+		}
+		if res.Files != 6 || len(res.TradingSystems) != 2 || res.TradingSystems[0].To != 107 || res.TradingSystems[1].To != 8 {
+			t.Errorf("%s: Import: bad result %+v", format, res)
+		}
+
+		ts, err := backend.GetTradingSystemInfo("mary", 107)
+		if err != nil || ts.Id != 107 || ts.Username != "mary" || ts.Name != "Breakout" {
+			t.Errorf("%s: bad info.json %+v, %v", format, ts, err)
+		}
+		if doc, err := backend.GetTradingSystemDoc("mary", 107); err != nil || doc != "Trend following" {
+			t.Errorf("%s: bad documentation %q, %v", format, doc, err)
+		}
+		if chart, err := backend.ReadEquityChart("mary", 107, "daily", backend.ChartFormatPNG); err != nil || string(chart) != "chart" {
+			t.Errorf("%s: bad equity chart %q, %v", format, chart, err)
+		}
+		if code, err := backend.ReadUserFile("mary", "8/code/main.el"); err != nil || string(code) != "buy next bar" {
+			t.Errorf("%s: bad code %q, %v", format, code, err)
+		}
+
+		//--- An id kept as is lives under both users, each owning its copy
+		for _, username := range []string{ "john", "mary" } {
+			if ts, err := backend.GetTradingSystemInfo(username, 8); err != nil || ts.Username != username || ts.Id != 8 {
+				t.Errorf("%s: bad info.json for %s: %+v, %v", format, username, ts, err)
+			}
+		}
+
+		//--- Existing trading systems are never overwritten
+		if _, err = Import(data, format, "john", nil, limit); !errors.Is(err, ErrConflict) {
+			t.Errorf("%s: Import (conflict): got %v", format, err)
+		}
+		if err = backend.CreateTradingSystemFiles("mary", 107, map[string][]byte{ "code/x.el": nil }); !errors.Is(err, backend.ErrTradingSystemExists) {
+			t.Errorf("%s: CreateTradingSystemFiles (existing): got %v", format, err)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestBackup_Invalid(t *testing.T) {
+	setup(t)
+	data := export(t, FormatZip)
+
+	tests := map[string]struct {
+		data  []byte
+		remap map[uint]uint
+		limit int64
+		err   error
+	}{
+		"remap unknown"  : { data, map[uint]uint{ 9: 109 },        limit, ErrInvalidRemap   },
+		"remap twice"    : { data, map[uint]uint{ 7: 8 },          limit, ErrInvalidRemap   },
+		"too large"      : { data, map[uint]uint{ 7: 107, 8: 108 }, 10,   ErrTooLarge       },
+		"not an archive" : { []byte("hello"), nil,                 limit, ErrInvalidArchive },
+		"tampered"       : { rewriteZip(t, data, "7/documentation.txt", "Mean reversion", ""), map[uint]uint{ 7: 107, 8: 108 }, limit, ErrInvalidArchive },
+		"extra entry"    : { rewriteZip(t, data, "", "", "9/info.json"),                      map[uint]uint{ 7: 107, 8: 108 }, limit, ErrInvalidArchive },
+		"traversal"      : { rewriteZip(t, data, "", "", "../mary/1/info.json"),              map[uint]uint{ 7: 107, 8: 108 }, limit, ErrInvalidArchive },
+	}
+
+	for name, test := range tests {
+		if _, err := Import(test.data, FormatZip, "mary", test.remap, test.limit); !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v, got %v", name, test.err, err)
+		}
+	}
+
+	if list, _ := backend.GetUserFiles("mary"); len(list) != 0 {
+		t.Errorf("Import: files written by a failed restore %v", list)
+	}
+
+	if _, err := Export(io.Discard, "john", "rar"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Export: expected an invalid format, got %v", err)
+	}
+}
+
+//=============================================================================
+
+func TestParseRemap(t *testing.T) {
+	remap, err := ParseRemap(" 7:107, 8:108")
+	if err != nil || len(remap) != 2 || remap[7] != 107 || remap[8] != 108 {
+		t.Errorf("ParseRemap: got %v, %v", remap, err)
+	}
+
+	for _, value := range []string{ "7", "7:", "a:1", "7:107,7:108", "-1:3" } {
+		if _, err = ParseRemap(value); !errors.Is(err, ErrInvalidRemap) {
+			t.Errorf("ParseRemap(%q): got %v", value, err)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRunCommand(t *testing.T) {
+	setup(t)
+	file := filepath.Join(t.TempDir(), "john.tgz")
+	out  := &bytes.Buffer{}
+
+	if err := RunCommand([]string{ CommandBackup, "john", file }, out, limit); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunCommand([]string{ CommandRestore, "mary", file, "7:107" }, out, limit); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Restored trading system 7 as 107") {
+		t.Errorf("RunCommand: bad output %q", out.String())
+	}
+
+	for _, args := range [][]string{
+		{ CommandBackup },
+		{ CommandBackup,  "john", file, "extra" },
+		{ CommandBackup,  "john", file +".rar" },
+		{ CommandRestore, "mary", file +".zip" },
+	} {
+		if err := RunCommand(args, out, limit); err == nil {
+			t.Errorf("RunCommand(%v): expected an error", args)
+		}
+	}
+
+	if _, err := os.Stat(file +".rar"); !os.IsNotExist(err) {
+		t.Errorf("RunCommand: file created for an invalid format, %v", err)
+	}
+}
+
+//=============================================================================
+//=== Copies a zip archive replacing the content of one entry or adding a new one
+
+func rewriteZip(t *testing.T, data []byte, name string, content string, extra string) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	zw  := zip.NewWriter(buf)
+
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entry, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if f.Name == name {
+			entry = []byte(content)
+		}
+
+		w, err := zw.Create(f.Name)
+		if err == nil {
+			_, err = w.Write(entry)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if extra != "" {
+		if w, err := zw.Create(extra); err != nil {
+			t.Fatal(err)
+		} else if _, err = w.Write([]byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+//=============================================================================
@@ -0,0 +1,173 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//=============================================================================
+//=== Command line access, for disaster recovery and environment cloning:
+//===
+//===   storage-manager backup  <username> <file>
+//===   storage-manager restore <username> <file> [remap]
+//===
+//=== The format is taken from the file extension (.tar.gz, .tgz or .zip)
+//=============================================================================
+
+const (
+	CommandBackup  = "backup"
+	CommandRestore = "restore"
+)
+
+var ErrUsage = errors.New("usage: storage-manager backup <username> <file> | restore <username> <file> [remap]")
+
+//=============================================================================
+
+func IsCommand(args []string) bool {
+	return len(args) > 0 && (args[0] == CommandBackup || args[0] == CommandRestore)
+}
+
+//=============================================================================
+//=== limit is the maximum uncompressed size of an archive to restore
+
+func RunCommand(args []string, out io.Writer, limit int64) error {
+	if !IsCommand(args) || len(args) < 3 {
+		return ErrUsage
+	}
+
+	username := args[1]
+	file     := args[2]
+
+	format, err := getFileFormat(file)
+	if err != nil {
+		return err
+	}
+
+	if args[0] == CommandBackup {
+		if len(args) != 3 {
+			return ErrUsage
+		}
+
+		return runBackup(out, username, file, format)
+	}
+
+	if len(args) > 4 {
+		return ErrUsage
+	}
+
+	remap := ""
+	if len(args) == 4 {
+		remap = args[3]
+	}
+
+	return runRestore(out, username, file, format, remap, limit)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func runBackup(out io.Writer, username string, file string, format string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	m, err := Export(f, username, format)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		_ = os.Remove(file)
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "Backed up %v: %v trading systems, %v files -> %v\n", username, len(m.TradingSystems), len(m.Files), file)
+	return err
+}
+
+//=============================================================================
+
+func runRestore(out io.Writer, username string, file string, format string, remap string, limit int64) error {
+	ids, err := ParseRemap(remap)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > limit {
+		return fmt.Errorf("%w: more than %v bytes", ErrTooLarge, limit)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	res, err := Import(data, format, username, ids, limit)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range res.TradingSystems {
+		_, err = fmt.Fprintf(out, "Restored trading system %v as %v\n", m.From, m.To)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(out, "Restored %v: %v files <- %v\n", username, res.Files, file)
+	return err
+}
+
+//=============================================================================
+
+func getFileFormat(file string) (string, error) {
+	name := strings.ToLower(file)
+
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return FormatTarGz, nil
+	case strings.HasSuffix(name, ".zip"):
+		return FormatZip, nil
+	}
+
+	return "", fmt.Errorf("%w: cannot guess the format of %v", ErrInvalidFormat, file)
+}
+
+//=============================================================================
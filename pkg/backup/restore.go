@@ -0,0 +1,371 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//=============================================================================
+//=== Restores an archive under a user, optionally giving new ids to the
+//=== trading systems. Everything is checked (manifest, checksums, paths and
+//=== existing trading systems) before the first file is written.
+//=============================================================================
+
+const MaxEntries = 100000
+
+//=============================================================================
+
+type Result struct {
+	Username       string     `json:"username"`
+	TradingSystems []*Mapping `json:"tradingSystems"`
+	Files          int        `json:"files"`
+}
+
+//=============================================================================
+
+type Mapping struct {
+	From uint `json:"from"`
+	To   uint `json:"to"`
+}
+
+//=============================================================================
+//=== limit is the maximum uncompressed size of the archive
+
+func Import(data []byte, format string, username string, remap map[uint]uint, limit int64) (*Result, error) {
+	err := CheckFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := readArchive(data, format, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := checkArchive(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := buildMappings(m, username, remap)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[uint]uint{}
+	for _, mapping := range res.TradingSystems {
+		ids[mapping.From] = mapping.To
+
+		_, err = backend.GetTradingSystemInfo(username, mapping.To)
+		if err == nil {
+			return nil, fmt.Errorf("%w: %v", ErrConflict, mapping.To)
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	files := map[uint]map[string][]byte{}
+
+	for _, file := range m.Files {
+		id, _      := getTradingSystemId(file.Path)
+		_, rest, _ := strings.Cut(file.Path, "/")
+
+		content := entries[file.Path]
+		if rest == backend.InfoFile {
+			content, err = rewriteInfo(content, username, ids[id])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if files[id] == nil {
+			files[id] = map[string][]byte{}
+		}
+		files[id][rest] = content
+	}
+
+	//--- The existence check is repeated under the lock of each trading system
+	for _, mapping := range res.TradingSystems {
+		err = backend.CreateTradingSystemFiles(username, mapping.To, files[mapping.From])
+		if err != nil {
+			if errors.Is(err, backend.ErrTradingSystemExists) {
+				return nil, fmt.Errorf("%w: %v", ErrConflict, mapping.To)
+			}
+			return nil, err
+		}
+
+		res.Files += len(files[mapping.From])
+	}
+
+	return res, nil
+}
+
+//=============================================================================
+//=== Parses a list like "7:107,8:108"
+
+func ParseRemap(value string) (map[uint]uint, error) {
+	remap := map[uint]uint{}
+	if value == "" {
+		return remap, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		from, to, found := strings.Cut(strings.TrimSpace(pair), ":")
+
+		fromId, err1 := strconv.ParseUint(from, 10, 32)
+		toId,   err2 := strconv.ParseUint(to,   10, 32)
+
+		if !found || err1 != nil || err2 != nil || toId == 0 {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRemap, pair)
+		}
+
+		if _, ok := remap[uint(fromId)]; ok {
+			return nil, fmt.Errorf("%w: trading system %v is remapped twice", ErrInvalidRemap, fromId)
+		}
+
+		remap[uint(fromId)] = uint(toId)
+	}
+
+	return remap, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func readArchive(data []byte, format string, limit int64) (map[string][]byte, error) {
+	entries := map[string][]byte{}
+	total   := int64(0)
+
+	add := func(name string, r io.Reader) error {
+		if len(entries) == MaxEntries {
+			return fmt.Errorf("%w: more than %v entries", ErrTooLarge, MaxEntries)
+		}
+
+		if _, ok := entries[name]; ok || name != path.Clean(name) {
+			return fmt.Errorf("%w: bad or duplicate entry %v", ErrInvalidArchive, name)
+		}
+
+		content, err := io.ReadAll(io.LimitReader(r, limit - total +1))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		total += int64(len(content))
+		if total > limit {
+			return fmt.Errorf("%w: more than %v bytes", ErrTooLarge, limit)
+		}
+
+		entries[name] = content
+		return nil
+	}
+
+	if format == FormatZip {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			if !f.Mode().IsRegular() {
+				return nil, fmt.Errorf("%w: not a regular file: %v", ErrInvalidArchive, f.Name)
+			}
+
+			r, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+			}
+
+			err = add(f.Name, r)
+			_ = r.Close()
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return entries, nil
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	tr := tar.NewReader(gr)
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+			err = add(h.Name, tr)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: not a regular file: %v", ErrInvalidArchive, h.Name)
+		}
+	}
+}
+
+//=============================================================================
+//=== The archive must hold exactly the files of the manifest
+
+func checkArchive(entries map[string][]byte) (*Manifest, error) {
+	data, ok := entries[ManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %v", ErrInvalidArchive, ManifestFile)
+	}
+
+	m := &Manifest{}
+	err := json.Unmarshal(data, m)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad manifest: %v", ErrInvalidArchive, err)
+	}
+
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("%w: unsupported manifest version %v", ErrInvalidArchive, m.Version)
+	}
+
+	if len(m.Files) != len(entries) -1 {
+		return nil, fmt.Errorf("%w: the manifest lists %v files, the archive holds %v", ErrInvalidArchive, len(m.Files), len(entries) -1)
+	}
+
+	for _, file := range m.Files {
+		if _, err = getTradingSystemId(file.Path); err != nil {
+			return nil, err
+		}
+
+		content, ok := entries[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing file %v", ErrInvalidArchive, file.Path)
+		}
+
+		sum := sha256.Sum256(content)
+		if int64(len(content)) != file.Size || hex.EncodeToString(sum[:]) != file.SHA256 {
+			return nil, fmt.Errorf("%w: checksum mismatch for %v", ErrInvalidArchive, file.Path)
+		}
+	}
+
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+
+	return m, nil
+}
+
+//=============================================================================
+
+func buildMappings(m *Manifest, username string, remap map[uint]uint) (*Result, error) {
+	res := &Result{
+		Username      : username,
+		TradingSystems: []*Mapping{},
+	}
+
+	sources := map[uint]bool{}
+	for _, file := range m.Files {
+		id, _ := getTradingSystemId(file.Path)
+		sources[id] = true
+	}
+
+	for from := range remap {
+		if !sources[from] {
+			return nil, fmt.Errorf("%w: trading system %v is not in the archive", ErrInvalidRemap, from)
+		}
+	}
+
+	targets := map[uint]bool{}
+
+	for from := range sources {
+		to, ok := remap[from]
+		if !ok {
+			to = from
+		}
+
+		if targets[to] {
+			return nil, fmt.Errorf("%w: trading system %v is restored twice", ErrInvalidRemap, to)
+		}
+
+		targets[to] = true
+		res.TradingSystems = append(res.TradingSystems, &Mapping{ From: from, To: to })
+	}
+
+	sort.Slice(res.TradingSystems, func(i, j int) bool {
+		return res.TradingSystems[i].From < res.TradingSystems[j].From
+	})
+
+	return res, nil
+}
+
+//=============================================================================
+//=== info.json must match the folder it is restored into
+
+func rewriteInfo(data []byte, username string, id uint) ([]byte, error) {
+	ts := backend.TradingSystem{}
+
+	err := json.Unmarshal(data, &ts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad %v: %v", ErrInvalidArchive, backend.InfoFile, err)
+	}
+
+	ts.Id       = id
+	ts.Username = username
+
+	return json.Marshal(&ts)
+}
+
+//=============================================================================
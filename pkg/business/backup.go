@@ -0,0 +1,99 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"errors"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backup"
+	"io"
+	"net/http"
+)
+
+//=============================================================================
+//=== Backup and restore of the whole tree of a user, see the backup package
+//=============================================================================
+
+func BackupUser(c *auth.Context, username string, format string, w io.Writer) (*backup.Manifest, error) {
+	c.Log.Info("BackupUser: Backing up user storage", "owner", username, "format", format)
+
+	m, err := backup.Export(w, username, format)
+	if err != nil {
+		c.Log.Error("BackupUser: Cannot back up user storage", "owner", username, "error", err)
+		return nil, convertBackupError(err)
+	}
+
+	c.Log.Info("BackupUser: User storage backed up", "owner", username, "tradingSystems", len(m.TradingSystems), "files", len(m.Files))
+	return m, nil
+}
+
+//=============================================================================
+//=== remap is a list like "7:107,8:108"
+
+func RestoreUser(c *auth.Context, username string, format string, data []byte, remap string) (*backup.Result, error) {
+	c.Log.Info("RestoreUser: Restoring user storage", "owner", username, "format", format, "remap", remap)
+
+	if maxSize := GetMaxBackupSize(c); int64(len(data)) > maxSize {
+		return nil, newAppError(http.StatusRequestEntityTooLarge, "Archive exceeds the maximum size of %v bytes", maxSize)
+	}
+
+	ids, err := backup.ParseRemap(remap)
+	if err != nil {
+		return nil, convertBackupError(err)
+	}
+
+	res, err := backup.Import(data, format, username, ids, GetMaxBackupSize(c))
+	if err != nil {
+		c.Log.Error("RestoreUser: Cannot restore user storage", "owner", username, "error", err)
+		return nil, convertBackupError(err)
+	}
+
+	c.Log.Info("RestoreUser: User storage restored", "owner", username, "tradingSystems", len(res.TradingSystems), "files", res.Files)
+	return res, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func convertBackupError(err error) error {
+	switch {
+	case errors.Is(err, backup.ErrInvalidFormat),
+		errors.Is(err, backup.ErrInvalidArchive),
+		errors.Is(err, backup.ErrInvalidRemap):
+		return newAppError(http.StatusBadRequest, "%v", err)
+	case errors.Is(err, backup.ErrConflict):
+		return newAppError(http.StatusConflict, "%v", err)
+	case errors.Is(err, backup.ErrTooLarge):
+		return newAppError(http.StatusRequestEntityTooLarge, "%v", err)
+	}
+
+	return convertServerError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,357 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"net/http"
+)
+
+//=============================================================================
+
+func GetCodeFiles(c *auth.Context, id uint) ([]*CodeFile, error) {
+	c.Log.Info("GetCodeFiles: Getting code files for trading system", "id", id)
+
+	err := checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("GetCodeFiles: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	files, err := backend.GetCodeFiles(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetCodeFiles: Cannot list code files", "id", id, "error", err)
+		return nil, err
+	}
+
+	list := []*CodeFile{}
+	for _, file := range files {
+		list = append(list, &CodeFile{
+			Name       : file.Name,
+			Size       : file.Size,
+			ModTime    : file.ModTime,
+			ContentType: getContentTypeFromName(file.Name),
+		})
+	}
+
+	c.Log.Info("GetCodeFiles: Operation complete", "id", id, "files", len(list))
+	return list, nil
+}
+
+//=============================================================================
+
+func GetCodeFile(c *auth.Context, id uint, name string) (*CodeFile, []byte, error) {
+	c.Log.Info("GetCodeFile: Getting code file for trading system", "id", id, "name", name)
+
+	err := validateFileName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := backend.GetCodeFileInfo(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetCodeFile: Cannot retrieve code file", "id", id, "name", name, "error", err)
+		return nil, nil, convertError(err, "Code file not found: %v", name)
+	}
+
+	data, err := backend.ReadCodeFile(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetCodeFile: Cannot read code file", "id", id, "name", name, "error", err)
+		return nil, nil, convertError(err, "Code file not found: %v", name)
+	}
+
+	c.Log.Info("GetCodeFile: Operation complete", "id", id, "name", name)
+
+	return &CodeFile{
+		Name       : name,
+		Size       : int64(len(data)),
+		ModTime    : info.ModTime,
+		ContentType: detectContentType(name, data),
+	}, data, nil
+}
+
+//=============================================================================
+
+func UploadCodeFile(c *auth.Context, id uint, name string, data []byte) (*CodeFile, error) {
+	c.Log.Info("UploadCodeFile: Uploading code file for trading system", "id", id, "name", name, "size", len(data))
+
+	err := validateFileName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxSize := GetMaxCodeSize(c); int64(len(data)) > maxSize {
+		c.Log.Error("UploadCodeFile: Code file is too big", "id", id, "name", name, "size", len(data), "maxSize", maxSize)
+		return nil, newAppError(http.StatusRequestEntityTooLarge, "Code file exceeds the maximum size of %v bytes", maxSize)
+	}
+
+	err = checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("UploadCodeFile: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	rev := &backend.Revision{
+		Author: c.Session.Username,
+	}
+
+	err = backend.SaveCodeFile(c.Session.Username, id, name, data, rev)
+	if err != nil {
+		c.Log.Error("UploadCodeFile: Cannot write code file", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	info, err := backend.GetCodeFileInfo(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("UploadCodeFile: Cannot retrieve code file", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	c.Log.Info("UploadCodeFile: Operation complete", "id", id, "name", name, "revision", rev.Revision)
+
+	return &CodeFile{
+		Name       : name,
+		Size       : info.Size,
+		ModTime    : info.ModTime,
+		ContentType: detectContentType(name, data),
+		Revision   : rev.Revision,
+	}, nil
+}
+
+//=============================================================================
+
+func DeleteCodeFile(c *auth.Context, id uint, name string) error {
+	c.Log.Info("DeleteCodeFile: Deleting code file for trading system", "id", id, "name", name)
+
+	err := validateFileName(name)
+	if err != nil {
+		return err
+	}
+
+	err = backend.DeleteCodeFile(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("DeleteCodeFile: Cannot delete code file", "id", id, "name", name, "error", err)
+		return convertError(err, "Code file not found: %v", name)
+	}
+
+	c.Log.Info("DeleteCodeFile: Operation complete", "id", id, "name", name)
+	return nil
+}
+
+//=============================================================================
+//=== Revisions
+//=============================================================================
+
+func GetCodeRevisions(c *auth.Context, id uint, name string) (*CodeRevisionsResponse, error) {
+	c.Log.Info("GetCodeRevisions: Getting revisions of code file", "id", id, "name", name)
+
+	err := checkCodeFileHistory(c, id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := backend.GetCodeRevisions(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetCodeRevisions: Cannot retrieve revisions", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	dep, err := backend.GetCodeDeployment(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetCodeRevisions: Cannot retrieve deployment", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	c.Log.Info("GetCodeRevisions: Operation complete", "id", id, "name", name, "revisions", len(list))
+
+	return &CodeRevisionsResponse{
+		Revisions : list,
+		Deployment: dep,
+	}, nil
+}
+
+//=============================================================================
+
+func GetCodeRevision(c *auth.Context, id uint, name string, rev int) (*CodeFile, []byte, error) {
+	c.Log.Info("GetCodeRevision: Getting revision of code file", "id", id, "name", name, "revision", rev)
+
+	err := validateFileName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, data, err := backend.GetCodeRevision(c.Session.Username, id, name, rev)
+	if err != nil {
+		c.Log.Error("GetCodeRevision: Cannot retrieve revision", "id", id, "name", name, "revision", rev, "error", err)
+		return nil, nil, convertError(err, "Code revision not found: %v", rev)
+	}
+
+	c.Log.Info("GetCodeRevision: Operation complete", "id", id, "name", name, "revision", rev)
+
+	return &CodeFile{
+		Name       : name,
+		Size       : int64(len(data)),
+		ModTime    : r.Timestamp,
+		ContentType: detectContentType(name, data),
+		Revision   : r.Revision,
+	}, data, nil
+}
+
+//=============================================================================
+
+func TagCodeRevision(c *auth.Context, id uint, name string, rev int, tr *TagRequest) (*backend.Revision, error) {
+	c.Log.Info("TagCodeRevision: Tagging revision of code file", "id", id, "name", name, "revision", rev, "tag", tr.Tag)
+
+	err := validateFileName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateTag(tr.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := backend.TagCodeRevision(c.Session.Username, id, name, rev, tr.Tag)
+	if errors.Is(err, backend.ErrTagExists) {
+		c.Log.Info("TagCodeRevision: Tag already used", "id", id, "name", name, "tag", tr.Tag)
+		return nil, newAppError(http.StatusConflict, "Tag is already used by another revision: %v", tr.Tag)
+	}
+	if err != nil {
+		c.Log.Error("TagCodeRevision: Cannot tag revision", "id", id, "name", name, "revision", rev, "error", err)
+		return nil, convertError(err, "Code revision not found: %v", rev)
+	}
+
+	c.Log.Info("TagCodeRevision: Operation complete", "id", id, "name", name, "revision", rev, "tag", tr.Tag)
+	return r, nil
+}
+
+//=============================================================================
+
+func RestoreCodeRevision(c *auth.Context, id uint, name string, rev int) (*backend.Revision, error) {
+	c.Log.Info("RestoreCodeRevision: Restoring revision of code file", "id", id, "name", name, "revision", rev)
+
+	err := validateFileName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	_, data, err := backend.GetCodeRevision(c.Session.Username, id, name, rev)
+	if err != nil {
+		c.Log.Error("RestoreCodeRevision: Cannot retrieve revision", "id", id, "name", name, "revision", rev, "error", err)
+		return nil, convertError(err, "Code revision not found: %v", rev)
+	}
+
+	r := &backend.Revision{
+		Author      : c.Session.Username,
+		RestoredFrom: rev,
+	}
+
+	err = backend.SaveCodeFile(c.Session.Username, id, name, data, r)
+	if err != nil {
+		c.Log.Error("RestoreCodeRevision: Cannot store code file", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	c.Log.Info("RestoreCodeRevision: Operation complete", "id", id, "name", name, "revision", rev, "newRevision", r.Revision)
+	return r, nil
+}
+
+//=============================================================================
+//=== Deployment
+//=============================================================================
+
+func GetCodeDeployment(c *auth.Context, id uint, name string) (*backend.Deployment, error) {
+	c.Log.Info("GetCodeDeployment: Getting deployed revision of code file", "id", id, "name", name)
+
+	err := checkCodeFileHistory(c, id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	dep, err := backend.GetCodeDeployment(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetCodeDeployment: Cannot retrieve deployment", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	if dep == nil {
+		return nil, newAppError(http.StatusNotFound, "Code file has never been deployed: %v", name)
+	}
+
+	c.Log.Info("GetCodeDeployment: Operation complete", "id", id, "name", name, "revision", dep.Revision)
+	return dep, nil
+}
+
+//=============================================================================
+
+func SetCodeDeployment(c *auth.Context, id uint, name string, dr *DeploymentRequest) (*backend.Deployment, error) {
+	c.Log.Info("SetCodeDeployment: Setting deployed revision of code file", "id", id, "name", name, "revision", dr.Revision)
+
+	err := validateFileName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dep := &backend.Deployment{
+		Revision: dr.Revision,
+		Author  : c.Session.Username,
+	}
+
+	err = backend.SetCodeDeployment(c.Session.Username, id, name, dep)
+	if err != nil {
+		c.Log.Error("SetCodeDeployment: Cannot set deployment", "id", id, "name", name, "revision", dr.Revision, "error", err)
+		return nil, convertError(err, "Code revision not found: %v", dr.Revision)
+	}
+
+	c.Log.Info("SetCodeDeployment: Operation complete", "id", id, "name", name, "revision", dr.Revision)
+	return dep, nil
+}
+
+//=============================================================================
+//=== Private functions
+//=============================================================================
+
+func checkCodeFileHistory(c *auth.Context, id uint, name string) error {
+	err := validateFileName(name)
+	if err != nil {
+		return err
+	}
+
+	list, err := backend.GetCodeRevisions(c.Session.Username, id, name)
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		return newAppError(http.StatusNotFound, "Code file not found: %v", name)
+	}
+
+	return nil
+}
+
+//=============================================================================
@@ -0,0 +1,171 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"testing"
+)
+
+//=============================================================================
+
+func TestCodeFiles(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	if _, err := UploadCodeFile(c, 1, "main.py", []byte("import os\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	info, data, err := GetCodeFile(c, 1, "main.py")
+	if err != nil || string(data) != "import os\n" || info.ContentType != "text/x-python; charset=utf-8" {
+		t.Fatalf("GetCodeFile: got %+v, %q, %v", info, data, err)
+	}
+
+	list, err := GetCodeFiles(c, 1)
+	if err != nil || len(list) != 1 || list[0].Size != 10 {
+		t.Fatalf("GetCodeFiles: got %v, %v", list, err)
+	}
+
+	if err = DeleteCodeFile(c, 1, "main.py"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = GetCodeFiles(c, 1)
+	if err != nil || len(list) != 0 {
+		t.Errorf("GetCodeFiles after delete: got %v, %v", list, err)
+	}
+}
+
+//=============================================================================
+
+func TestCodeFiles_Validation(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+	c.Config = &app.Config{ Storage: app.Storage{ MaxCodeSize: 4 } }
+
+	tests := []struct {
+		ctx  *auth.Context
+		name string
+		data string
+		code int
+	}{
+		{ c,                 "../other.el", "x",     400 },
+		{ c,                 "a/b.el",      "x",     400 },
+		{ c,                 ".hidden",     "x",     400 },
+		{ c,                 "",            "x",     400 },
+		{ c,                 "main.el",     "12345", 413 },
+		{ newContext("jane"), "main.el",    "x",     404 },
+	}
+
+	for _, test := range tests {
+		_, err := UploadCodeFile(test.ctx, 1, test.name, []byte(test.data))
+		if !isAppError(err, test.code) {
+			t.Errorf("UploadCodeFile(%q): expected %d, got %v", test.name, test.code, err)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestCodeRevisions(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	for _, code := range []string{ "v1", "v2", "v3" } {
+		if _, err := UploadCodeFile(c, 1, "main.el", []byte(code)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := GetCodeRevisions(c, 1, "main.el")
+	if err != nil || len(res.Revisions) != 3 || res.Deployment != nil {
+		t.Fatalf("GetCodeRevisions: got %+v, %v", res, err)
+	}
+
+	info, data, err := GetCodeRevision(c, 1, "main.el", 2)
+	if err != nil || string(data) != "v2" || info.Revision != 2 {
+		t.Errorf("GetCodeRevision: got %+v, %q, %v", info, data, err)
+	}
+
+	//--- Tags
+
+	r, err := TagCodeRevision(c, 1, "main.el", 2, &TagRequest{ Tag: "live-2026-09" })
+	if err != nil || len(r.Tags) != 1 || r.Tags[0] != "live-2026-09" {
+		t.Fatalf("TagCodeRevision: got %+v, %v", r, err)
+	}
+
+	if _, err = TagCodeRevision(c, 1, "main.el", 2, &TagRequest{ Tag: "live-2026-09" }); err != nil {
+		t.Errorf("TagCodeRevision (same revision): expected no error, got %v", err)
+	}
+	if _, err = TagCodeRevision(c, 1, "main.el", 3, &TagRequest{ Tag: "live-2026-09" }); !isAppError(err, 409) {
+		t.Errorf("TagCodeRevision (other revision): expected 409, got %v", err)
+	}
+	if _, err = TagCodeRevision(c, 1, "main.el", 3, &TagRequest{ Tag: "bad tag!" }); !isAppError(err, 400) {
+		t.Errorf("TagCodeRevision (bad tag): expected 400, got %v", err)
+	}
+	if _, err = TagCodeRevision(c, 1, "main.el", 8, &TagRequest{ Tag: "v8" }); !isAppError(err, 404) {
+		t.Errorf("TagCodeRevision (missing revision): expected 404, got %v", err)
+	}
+
+	//--- Deployment
+
+	if _, err = GetCodeDeployment(c, 1, "main.el"); !isAppError(err, 404) {
+		t.Errorf("GetCodeDeployment (never deployed): expected 404, got %v", err)
+	}
+
+	dep, err := SetCodeDeployment(c, 1, "main.el", &DeploymentRequest{ Revision: 2 })
+	if err != nil || dep.Revision != 2 || dep.Author != "john" {
+		t.Fatalf("SetCodeDeployment: got %+v, %v", dep, err)
+	}
+
+	if _, err = SetCodeDeployment(c, 1, "main.el", &DeploymentRequest{ Revision: 9 }); !isAppError(err, 404) {
+		t.Errorf("SetCodeDeployment (missing revision): expected 404, got %v", err)
+	}
+
+	dep, err = GetCodeDeployment(c, 1, "main.el")
+	if err != nil || dep.Revision != 2 {
+		t.Errorf("GetCodeDeployment: got %+v, %v", dep, err)
+	}
+
+	//--- Restore
+
+	r, err = RestoreCodeRevision(c, 1, "main.el", 1)
+	if err != nil || r.Revision != 4 || r.RestoredFrom != 1 {
+		t.Fatalf("RestoreCodeRevision: got %+v, %v", r, err)
+	}
+
+	_, data, err = GetCodeFile(c, 1, "main.el")
+	if err != nil || string(data) != "v1" {
+		t.Errorf("GetCodeFile after restore: got %q, %v", data, err)
+	}
+
+	if _, err = GetCodeRevisions(newContext("jane"), 1, "main.el"); !isAppError(err, 404) {
+		t.Errorf("GetCodeRevisions (other user): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,269 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/backup"
+	"github.com/bit-fever/storage-manager/pkg/imaging"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//=============================================================================
+
+const (
+	DefaultMaxCodeSize   =  1 * 1024 * 1024
+	DefaultMaxReportSize = 20 * 1024 * 1024
+	DefaultMaxImageSize  =  5 * 1024 * 1024
+
+	DefaultCacheControl  = "private, no-cache"
+)
+
+//=============================================================================
+//=== Types not (reliably) recognized by http.DetectContentType
+
+var contentTypes = map[string]string{
+	".el"  : "text/x-easylanguage; charset=utf-8",
+	".els" : "text/x-easylanguage; charset=utf-8",
+	".eld" : "application/octet-stream",
+	".pla" : "text/x-powerlanguage; charset=utf-8",
+	".pln" : "text/x-powerlanguage; charset=utf-8",
+	".py"  : "text/x-python; charset=utf-8",
+	".mq4" : "text/x-mql; charset=utf-8",
+	".mq5" : "text/x-mql; charset=utf-8",
+	".cs"  : "text/x-csharp; charset=utf-8",
+	".pine": "text/x-pine; charset=utf-8",
+	".txt" : "text/plain; charset=utf-8",
+	".json": "application/json",
+	".csv" : "text/csv; charset=utf-8",
+}
+
+//=============================================================================
+
+var chartContentTypes = map[string]string{
+	backend.ChartFormatPNG : "image/png",
+	backend.ChartFormatSVG : "image/svg+xml",
+	backend.ChartFormatWebP: "image/webp",
+}
+
+//=============================================================================
+
+func detectContentType(name string, data []byte) string {
+	if ct := getContentTypeFromName(name); ct != "" {
+		return ct
+	}
+
+	return http.DetectContentType(data)
+}
+
+//=============================================================================
+
+func getContentTypeFromName(name string) string {
+	return contentTypes[strings.ToLower(path.Ext(name))]
+}
+
+//=============================================================================
+//=== Charts are sent by the portfolio trader, which historically sent only
+//=== PNG files: anything not recognized as SVG or WebP is stored as PNG
+
+func detectChartFormat(data []byte) string {
+	if imaging.IsWebP(data) {
+		return backend.ChartFormatWebP
+	}
+
+	if isSVG(data) {
+		return backend.ChartFormatSVG
+	}
+
+	return backend.ChartFormatPNG
+}
+
+//=============================================================================
+
+func isSVG(data []byte) bool {
+	head := data[:min(len(data), 1024)]
+	head  = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	head  = bytes.TrimSpace(head)
+
+	if bytes.HasPrefix(head, []byte("<svg")) {
+		return true
+	}
+
+	return (bytes.HasPrefix(head, []byte("<?xml")) || bytes.HasPrefix(head, []byte("<!DOCTYPE svg"))) &&
+		bytes.Contains(head, []byte("<svg"))
+}
+
+//=============================================================================
+
+func ComputeHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+//=============================================================================
+//=== File names are used as they are in the storage: only a plain name with
+//=== a conservative set of characters is accepted
+
+func validateFileName(name string) error {
+	if name == "" || len(name) > 128 || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".temp") {
+		return newAppError(http.StatusBadRequest, "Invalid file name: %v", name)
+	}
+
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') &&
+			r != '.' && r != '-' && r != '_' && r != ' ' {
+			return newAppError(http.StatusBadRequest, "Invalid file name: %v", name)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func validateTag(tag string) error {
+	if tag == "" || len(tag) > 64 {
+		return newAppError(http.StatusBadRequest, "Invalid tag: %v", tag)
+	}
+
+	for _, r := range tag {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') &&
+			r != '.' && r != '-' && r != '_' {
+			return newAppError(http.StatusBadRequest, "Invalid tag: %v", tag)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+//=== Chart types become part of file names
+
+func validateChartType(chartType string) error {
+	if chartType == "" || len(chartType) > 32 {
+		return newAppError(http.StatusBadRequest, "Invalid chart type: %v", chartType)
+	}
+
+	for _, r := range chartType {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '-' && r != '_' {
+			return newAppError(http.StatusBadRequest, "Invalid chart type: %v", chartType)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+const MaxProducerVersion = 64
+
+//=============================================================================
+
+func validateChartMetadata(chartType string, ecm *backend.EquityChartMetadata) error {
+	if ecm.DataFrom != nil && ecm.DataTo != nil && ecm.DataFrom.After(*ecm.DataTo) {
+		return newAppError(http.StatusBadRequest, "Data range of chart %v ends before it starts", chartType)
+	}
+
+	if ecm.Trades != nil && *ecm.Trades < 0 {
+		return newAppError(http.StatusBadRequest, "Number of trades of chart %v is negative", chartType)
+	}
+
+	if len(ecm.ProducerVersion) > MaxProducerVersion {
+		return newAppError(http.StatusBadRequest, "Producer version of chart %v is too long (max %v characters)", chartType, MaxProducerVersion)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func getStorageConfig(c *auth.Context) *app.Storage {
+	if cfg, ok := c.Config.(*app.Config); ok {
+		return &cfg.Storage
+	}
+
+	return &app.Storage{}
+}
+
+//=============================================================================
+
+func GetMaxCodeSize(c *auth.Context) int64 {
+	if size := getStorageConfig(c).MaxCodeSize; size > 0 {
+		return size
+	}
+
+	return DefaultMaxCodeSize
+}
+
+//=============================================================================
+
+func GetMaxReportSize(c *auth.Context) int64 {
+	if size := getStorageConfig(c).MaxReportSize; size > 0 {
+		return size
+	}
+
+	return DefaultMaxReportSize
+}
+
+//=============================================================================
+
+func GetMaxBackupSize(c *auth.Context) int64 {
+	return backup.GetMaxSize(getStorageConfig(c))
+}
+
+//=============================================================================
+
+func GetMaxImageSize(c *auth.Context) int64 {
+	if size := getStorageConfig(c).MaxImageSize; size > 0 {
+		return size
+	}
+
+	return DefaultMaxImageSize
+}
+
+//=============================================================================
+
+func GetChartHistory(c *auth.Context) int {
+	return max(getStorageConfig(c).ChartHistory, 0)
+}
+
+//=============================================================================
+
+func GetCacheControl(c *auth.Context) string {
+	if cc := getStorageConfig(c).CacheControl; cc != "" {
+		return cc
+	}
+
+	return DefaultCacheControl
+}
+
+//=============================================================================
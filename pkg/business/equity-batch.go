@@ -0,0 +1,87 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"errors"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"net/http"
+	"strconv"
+)
+
+//=============================================================================
+
+const MaxBatchSize = 200
+
+//=============================================================================
+//=== Charts of several trading systems. Each trading system is looked up
+//=== with the caller's username, exactly like GetEquityChart: charts of other
+//=== users are reported as not found
+
+func GetEquityChartBatch(c *auth.Context, ids []uint, chartType string, formats []string, fallback bool) ([]*BatchChartResult, error) {
+	c.Log.Info("GetEquityChartBatch: Getting equity charts", "ids", len(ids), "type", chartType)
+
+	if len(ids) == 0 || len(ids) > MaxBatchSize {
+		return nil, newAppError(http.StatusBadRequest, "The number of trading systems must be between 1 and %v", MaxBatchSize)
+	}
+
+	err := validateChartType(chartType)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*BatchChartResult
+
+	for _, id := range ids {
+		res := &BatchChartResult{ Id: id, Status: http.StatusOK }
+
+		ec, err := GetEquityChart(c, id, chartType, formats, fallback)
+		if err != nil {
+			var ae req.AppError
+			if !errors.As(err, &ae) || (ae.Code != http.StatusNotFound && ae.Code != http.StatusNotAcceptable) {
+				c.Log.Error("GetEquityChartBatch: Cannot get equity chart", "id", id, "type", chartType, "error", err)
+				return nil, err
+			}
+
+			res.Status  = ae.Code
+			res.Message = ae.Message
+		} else {
+			res.Name        = strconv.Itoa(int(id)) +"-"+ chartType +"-equity-chart."+ ec.Format
+			res.Format      = ec.Format
+			res.Hash        = ec.Hash
+			res.Placeholder = ec.Placeholder
+			res.Chart       = ec
+		}
+
+		list = append(list, res)
+	}
+
+	c.Log.Info("GetEquityChartBatch: Operation complete", "ids", len(ids), "type", chartType)
+	return list, nil
+}
+
+//=============================================================================
@@ -0,0 +1,90 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"testing"
+)
+
+//=============================================================================
+
+func TestGetEquityChartBatch(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	for _, ts := range []*backend.TradingSystem{
+		{ Id: 2, Username: "john", Name: "Mean reversion" },
+		{ Id: 3, Username: "jane", Name: "Jane's system"  },
+	} {
+		if err := backend.AddTradingSystem(ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, id := range []uint{ 1, 3 } {
+		req := NewEquityRequest()
+		req.Username = "john"
+		if id == 3 {
+			req.Username = "jane"
+		}
+		req.Images["daily"] = []byte("png")
+
+		if _, err := SetEquityCharts(newContext("portfolio-trader"), id, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := GetEquityChartBatch(c, []uint{ 1, 2, 3 }, "daily", nil, false)
+	if err != nil || len(list) != 3 {
+		t.Fatalf("GetEquityChartBatch: got %v, %v", list, err)
+	}
+
+	if list[0].Status != 200 || list[0].Name != "1-daily-equity-chart.png" || list[0].Chart == nil {
+		t.Errorf("GetEquityChartBatch: bad result for 1: %+v", list[0])
+	}
+
+	//--- Missing chart and chart of another user look the same
+	for _, res := range list[1:] {
+		if res.Status != 404 || res.Chart != nil {
+			t.Errorf("GetEquityChartBatch: bad result for %d: %+v", res.Id, res)
+		}
+	}
+
+	list, err = GetEquityChartBatch(c, []uint{ 2 }, "daily", nil, true)
+	if err != nil || !list[0].Placeholder || string(list[0].Chart.Data) != string(defaultChart) {
+		t.Errorf("GetEquityChartBatch (fallback): got %+v, %v", list, err)
+	}
+
+	if _, err = GetEquityChartBatch(c, nil, "daily", nil, false); !isAppError(err, 400) {
+		t.Errorf("GetEquityChartBatch (no ids): expected 400, got %v", err)
+	}
+	if _, err = GetEquityChartBatch(c, make([]uint, MaxBatchSize +1), "daily", nil, false); !isAppError(err, 400) {
+		t.Errorf("GetEquityChartBatch (too many ids): expected 400, got %v", err)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,104 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"time"
+)
+
+//=============================================================================
+
+func GetEquityChartSnapshots(c *auth.Context, id uint, chartType string) ([]*EquityChartSnapshot, error) {
+	c.Log.Info("GetEquityChartSnapshots: Getting equity chart snapshots", "id", id, "type", chartType)
+
+	err := validateChartType(chartType)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("GetEquityChartSnapshots: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	snapshots, err := backend.GetEquityChartSnapshots(c.Session.Username, id, chartType)
+	if err != nil {
+		c.Log.Error("GetEquityChartSnapshots: Cannot list snapshots", "id", id, "type", chartType, "error", err)
+		return nil, err
+	}
+
+	list := []*EquityChartSnapshot{}
+
+	for _, cs := range snapshots {
+		ecm, err := backend.ReadEquityChartSnapshotMetadata(c.Session.Username, id, chartType, cs)
+		if err != nil {
+			c.Log.Error("GetEquityChartSnapshots: Cannot read snapshot metadata", "id", id, "type", chartType, "timestamp", cs.Timestamp, "error", err)
+			return nil, err
+		}
+
+		list = append(list, &EquityChartSnapshot{
+			Timestamp  : cs.Timestamp,
+			Format     : cs.Format,
+			ContentType: chartContentTypes[cs.Format],
+			Size       : cs.Size,
+			Metadata   : ecm,
+		})
+	}
+
+	c.Log.Info("GetEquityChartSnapshots: Operation complete", "id", id, "type", chartType, "snapshots", len(list))
+	return list, nil
+}
+
+//=============================================================================
+
+func GetEquityChartSnapshot(c *auth.Context, id uint, chartType string, timestamp time.Time) (*EquityChart, error) {
+	c.Log.Info("GetEquityChartSnapshot: Getting equity chart snapshot", "id", id, "type", chartType, "timestamp", timestamp)
+
+	err := validateChartType(chartType)
+	if err != nil {
+		return nil, err
+	}
+
+	cs, data, err := backend.ReadEquityChartSnapshot(c.Session.Username, id, chartType, timestamp)
+	if err != nil {
+		c.Log.Error("GetEquityChartSnapshot: Cannot read snapshot", "id", id, "type", chartType, "timestamp", timestamp, "error", err)
+		return nil, convertError(err, "Equity chart snapshot not found: %v at %v", chartType, timestamp.UTC().Format(time.RFC3339Nano))
+	}
+
+	return &EquityChart{
+		Type       : chartType,
+		Format     : cs.Format,
+		ContentType: chartContentTypes[cs.Format],
+		Data       : data,
+		Hash       : ComputeHash(data),
+		ModTime    : cs.Timestamp,
+	}, nil
+}
+
+//=============================================================================
@@ -0,0 +1,109 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"testing"
+)
+
+//=============================================================================
+
+func TestEquityChartSnapshots(t *testing.T) {
+	setup(t)
+
+	c := newContext("portfolio-trader")
+	c.Config = &app.Config{ Storage: app.Storage{ ChartHistory: 2 } }
+
+	trades := 10
+
+	for _, version := range []string{ "v1", "v2", "v3" } {
+		req := NewEquityRequest()
+		req.Username = "john"
+		req.Images["daily"]   = []byte("daily-"+ version)
+		req.Metadata["daily"] = &backend.EquityChartMetadata{ Trades: &trades, ProducerVersion: version }
+
+		if _, err := SetEquityCharts(c, 1, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	john := newContext("john")
+
+	list, err := GetEquityChartSnapshots(john, 1, "daily")
+	if err != nil || len(list) != 2 {
+		t.Fatalf("GetEquityChartSnapshots: expected 2 snapshots, got %v, %v", list, err)
+	}
+
+	if !list[0].Timestamp.Before(list[1].Timestamp) || list[1].Metadata == nil || list[1].Metadata.ProducerVersion != "v3" {
+		t.Errorf("GetEquityChartSnapshots: bad snapshots %+v, %+v", list[0], list[1])
+	}
+
+	for i, expected := range []string{ "daily-v2", "daily-v3" } {
+		ec, err := GetEquityChartSnapshot(john, 1, "daily", list[i].Timestamp)
+		if err != nil || string(ec.Data) != expected || ec.Format != backend.ChartFormatPNG || !ec.ModTime.Equal(list[i].Timestamp) {
+			t.Errorf("GetEquityChartSnapshot(%d): got %+v, %v", i, ec, err)
+		}
+	}
+
+	if _, err = GetEquityChartSnapshot(john, 1, "daily", list[0].Timestamp.Add(-1)); !isAppError(err, 404) {
+		t.Errorf("GetEquityChartSnapshot (missing): expected 404, got %v", err)
+	}
+	if _, err = GetEquityChartSnapshots(newContext("jane"), 1, "daily"); !isAppError(err, 404) {
+		t.Errorf("GetEquityChartSnapshots (other user): expected 404, got %v", err)
+	}
+
+	//--- No history is kept by default
+
+	req := NewEquityRequest()
+	req.Username = "john"
+	req.Images["weekly"] = []byte("weekly-v1")
+
+	if _, err = SetEquityCharts(newContext("portfolio-trader"), 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if list, err = GetEquityChartSnapshots(john, 1, "weekly"); err != nil || len(list) != 0 {
+		t.Errorf("GetEquityChartSnapshots (weekly): got %v, %v", list, err)
+	}
+
+	//--- Lowering the history prunes the existing snapshots on the next write
+
+	req = NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"] = []byte("daily-v4")
+
+	if _, err = SetEquityCharts(newContext("portfolio-trader"), 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if list, err = GetEquityChartSnapshots(john, 1, "daily"); err != nil || len(list) != 0 {
+		t.Errorf("GetEquityChartSnapshots (history 0): got %v, %v", list, err)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,264 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/chart"
+	"net/http"
+)
+
+//=============================================================================
+
+const (
+	DefaultChartWidth  = 800
+	DefaultChartHeight = 400
+)
+
+//=============================================================================
+
+func GetEquityData(c *auth.Context, id uint, chartType string, format string) (*EquityDataFile, []byte, error) {
+	c.Log.Info("GetEquityData: Getting equity data for trading system", "id", id, "type", chartType, "format", format)
+
+	err := validateChartType(chartType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	points, file, err := readEquityData(c, id, chartType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	edf := &EquityDataFile{
+		Name   : chartType +"-equity-data."+ format,
+		ModTime: file.ModTime,
+	}
+
+	var data []byte
+
+	switch format {
+	case chart.FormatJSON:
+		edf.ContentType = "application/json"
+		data, err = json.Marshal(points)
+	case chart.FormatCSV:
+		edf.ContentType = "text/csv; charset=utf-8"
+		data = chart.ToCSV(points)
+	default:
+		return nil, nil, newAppError(http.StatusBadRequest, "Invalid format (allowed are json, csv): %v", format)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.Log.Info("GetEquityData: Operation complete", "id", id, "type", chartType, "points", len(points))
+	return edf, data, nil
+}
+
+//=============================================================================
+//=== Rendered charts are cached with the hash of the series
+
+func RenderEquityChart(c *auth.Context, id uint, chartType string, format string, width int, height int) (*EquityChart, error) {
+	c.Log.Info("RenderEquityChart: Rendering equity chart for trading system", "id", id, "type", chartType, "format", format)
+
+	err := validateChartType(chartType)
+	if err != nil {
+		return nil, err
+	}
+
+	if format != chart.FormatPNG && format != chart.FormatSVG {
+		return nil, newAppError(http.StatusBadRequest, "Invalid format (allowed are png, svg): %v", format)
+	}
+
+	if width  == 0 { width  = DefaultChartWidth  }
+	if height == 0 { height = DefaultChartHeight }
+
+	if width < 0 || width > MaxResizeSize || height < 0 || height > MaxResizeSize {
+		return nil, newAppError(http.StatusBadRequest, "Width and height must be between 0 and %v", MaxResizeSize)
+	}
+
+	if width < chart.MinWidth || height < chart.MinHeight {
+		return nil, newAppError(http.StatusBadRequest, "Chart size must be at least %vx%v", chart.MinWidth, chart.MinHeight)
+	}
+
+	raw, err := backend.ReadEquityData(c.Session.Username, id, chartType)
+	if err != nil {
+		c.Log.Error("RenderEquityChart: Cannot read equity data", "id", id, "type", chartType, "error", err)
+		return nil, convertError(err, "Equity data not found: %v", chartType)
+	}
+
+	file, err := backend.GetEquityDataInfo(c.Session.Username, id, chartType)
+	if err != nil {
+		return nil, convertError(err, "Equity data not found: %v", chartType)
+	}
+
+	key  := fmt.Sprintf("%s-%dx%d.%s", ComputeHash(raw), width, height, format)
+	data, err := backend.ReadVariant(c.Session.Username, id, backend.VariantEquityData, chartType, key)
+
+	//--- Stored data has been validated on write: failures are server faults
+	if err != nil {
+		var points []chart.Point
+		points, err = chart.Parse(raw, chart.FormatJSON)
+		if err == nil {
+			data, err = chart.Render(points, format, width, height)
+		}
+		if err != nil {
+			c.Log.Error("RenderEquityChart: Cannot render equity chart", "id", id, "type", chartType, "error", err)
+			return nil, req.NewServerErrorByError(err)
+		}
+
+		err = backend.WriteVariant(c.Session.Username, id, backend.VariantEquityData, chartType, key, data)
+		if err != nil {
+			c.Log.Warn("RenderEquityChart: Cannot cache equity chart", "id", id, "type", chartType, "error", err)
+		}
+	}
+
+	c.Log.Info("RenderEquityChart: Operation complete", "id", id, "type", chartType, "size", len(data))
+
+	return &EquityChart{
+		Type       : chartType,
+		Format     : format,
+		ContentType: chartContentTypes[format],
+		Data       : data,
+		Hash       : ComputeHash(data),
+		ModTime    : file.ModTime,
+	}, nil
+}
+
+//=============================================================================
+// Called by Portfolio trader
+
+func SetEquityData(c *auth.Context, id uint, r *EquityDataRequest) error {
+	c.Log.Info("SetEquityData: Setting equity data for trading system", "id", id, "username", r.Username)
+
+	format := r.Format
+	if format == "" {
+		format = chart.FormatJSON
+	}
+
+	if format != chart.FormatJSON && format != chart.FormatCSV {
+		return newAppError(http.StatusBadRequest, "Invalid format (allowed are json, csv): %v", format)
+	}
+
+	err := checkServiceCaller(c, "SetEquityData", r.Username, id)
+	if err != nil {
+		c.Log.Error("SetEquityData: Caller not allowed", "id", id, "username", r.Username, "error", err)
+		return err
+	}
+
+	//--- Everything is validated before writing anything
+
+	series := map[string][]byte{}
+
+	for chartType, content := range r.Series {
+		err = validateChartType(chartType)
+		if err != nil {
+			return err
+		}
+
+		points, err := chart.Parse([]byte(content), format)
+		if err != nil {
+			return newAppError(http.StatusBadRequest, "Invalid equity data for %v: %v", chartType, err.Error())
+		}
+
+		series[chartType], err = json.Marshal(points)
+		if err != nil {
+			return err
+		}
+	}
+
+	for chartType, data := range series {
+		err = backend.WriteEquityData(r.Username, id, chartType, data)
+		if err != nil {
+			c.Log.Error("SetEquityData: Cannot write equity data", "id", id, "type", chartType, "error", err)
+			return err
+		}
+	}
+
+	c.Log.Info("SetEquityData: Equity data set", "id", id, "username", r.Username, "types", len(series))
+	return nil
+}
+
+//=============================================================================
+// Called by Portfolio trader
+
+func DeleteEquityData(c *auth.Context, id uint, r *EquityDataRequest) error {
+	c.Log.Info("DeleteEquityData: Deleting equity data for trading system", "id", id, "username", r.Username)
+
+	err := checkServiceCaller(c, "DeleteEquityData", r.Username, id)
+	if err != nil {
+		c.Log.Error("DeleteEquityData: Caller not allowed", "id", id, "username", r.Username, "error", err)
+		return err
+	}
+
+	types, err := backend.GetEquityDataTypes(r.Username, id)
+	if err != nil {
+		c.Log.Error("DeleteEquityData: Cannot list equity data", "id", id, "username", r.Username, "error", err)
+		return convertError(err, "Trading system not found: %v", id)
+	}
+
+	for _, chartType := range types {
+		err = backend.DeleteEquityData(r.Username, id, chartType)
+		if err != nil {
+			c.Log.Error("DeleteEquityData: Cannot delete equity data", "id", id, "type", chartType, "error", err)
+			return err
+		}
+	}
+
+	c.Log.Info("DeleteEquityData: Equity data deleted", "id", id, "username", r.Username, "types", len(types))
+	return nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func readEquityData(c *auth.Context, id uint, chartType string) ([]chart.Point, *backend.FileInfo, error) {
+	file, err := backend.GetEquityDataInfo(c.Session.Username, id, chartType)
+	if err != nil {
+		return nil, nil, convertError(err, "Equity data not found: %v", chartType)
+	}
+
+	raw, err := backend.ReadEquityData(c.Session.Username, id, chartType)
+	if err != nil {
+		c.Log.Error("readEquityData: Cannot read equity data", "id", id, "type", chartType, "error", err)
+		return nil, nil, convertError(err, "Equity data not found: %v", chartType)
+	}
+
+	var points []chart.Point
+	err = json.Unmarshal(raw, &points)
+
+	return points, file, err
+}
+
+//=============================================================================
@@ -0,0 +1,146 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/chart"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+const equityCSV = "timestamp,equity,drawdown\n2024-01-01,1000,0\n2024-01-02,900,-100\n2024-01-03,1100,0\n"
+
+//=============================================================================
+
+func TestEquityData(t *testing.T) {
+	setup(t)
+	service := newContext("portfolio-trader")
+	c       := newContext("john")
+
+	r := NewEquityDataRequest()
+	r.Username = "john"
+	r.Format   = chart.FormatCSV
+	r.Series["daily"] = equityCSV
+
+	if err := SetEquityData(service, 1, r); err != nil {
+		t.Fatal(err)
+	}
+
+	edf, data, err := GetEquityData(c, 1, "daily", chart.FormatCSV)
+	if err != nil || edf.ContentType != "text/csv; charset=utf-8" || !strings.Contains(string(data), "2024-01-02T00:00:00Z,900,-100") {
+		t.Errorf("GetEquityData (csv): got %+v, %q, %v", edf, data, err)
+	}
+
+	edf, data, err = GetEquityData(c, 1, "daily", chart.FormatJSON)
+	if err != nil || edf.Name != "daily-equity-data.json" || !strings.HasPrefix(string(data), `[{"time":"2024-01-01T00:00:00Z"`) {
+		t.Errorf("GetEquityData (json): got %+v, %q, %v", edf, data, err)
+	}
+
+	ec, err := RenderEquityChart(c, 1, "daily", chart.FormatSVG, 300, 150)
+	if err != nil || ec.ContentType != "image/svg+xml" || !strings.HasPrefix(string(ec.Data), "<svg") {
+		t.Fatalf("RenderEquityChart (svg): got %+v, %v", ec, err)
+	}
+
+	ec, err = RenderEquityChart(c, 1, "daily", chart.FormatPNG, 0, 0)
+	if err != nil || ec.ContentType != "image/png" {
+		t.Fatalf("RenderEquityChart (png): got %+v, %v", ec, err)
+	}
+
+	//--- New data must drop the cached charts
+
+	r.Series["daily"] = "timestamp,equity\n2024-01-01,5\n"
+	if err = SetEquityData(service, 1, r); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := RenderEquityChart(c, 1, "daily", chart.FormatPNG, 0, 0)
+	if err != nil || again.Hash == ec.Hash {
+		t.Errorf("RenderEquityChart: cached chart not invalidated")
+	}
+
+	if err = DeleteEquityData(service, 1, r); err != nil {
+		t.Fatal(err)
+	}
+
+	types, err := backend.GetEquityDataTypes("john", 1)
+	if err != nil || len(types) != 0 {
+		t.Errorf("DeleteEquityData: data still present: %v, %v", types, err)
+	}
+	if _, err = RenderEquityChart(c, 1, "daily", chart.FormatPNG, 0, 0); !isAppError(err, 404) {
+		t.Errorf("RenderEquityChart (deleted): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
+
+func TestEquityData_Validation(t *testing.T) {
+	setup(t)
+	service := newContext("portfolio-trader")
+
+	tests := []struct {
+		username string
+		format   string
+		series   map[string]string
+		code     int
+	}{
+		{ "john", "xml",           map[string]string{ "daily": equityCSV },      400 },
+		{ "john", chart.FormatCSV, map[string]string{ "../x":  equityCSV },      400 },
+		{ "john", chart.FormatCSV, map[string]string{ "daily": "a,b\n1,2\n" }, 400 },
+		{ "john", "",              map[string]string{ "daily": equityCSV },      400 },
+		{ "jane", chart.FormatCSV, map[string]string{ "daily": equityCSV },      404 },
+	}
+
+	for _, test := range tests {
+		r := &EquityDataRequest{ Username: test.username, Format: test.format, Series: test.series }
+		if err := SetEquityData(service, 1, r); !isAppError(err, test.code) {
+			t.Errorf("SetEquityData(%+v): expected %d, got %v", r, test.code, err)
+		}
+	}
+
+	c := newContext("john")
+	if _, err := RenderEquityChart(c, 1, "daily", "gif", 0, 0); !isAppError(err, 400) {
+		t.Errorf("RenderEquityChart (bad format): expected 400, got %v", err)
+	}
+	if _, err := RenderEquityChart(c, 1, "daily", chart.FormatPNG, MaxResizeSize +1, 0); !isAppError(err, 400) {
+		t.Errorf("RenderEquityChart (too big): expected 400, got %v", err)
+	}
+	if _, err := RenderEquityChart(c, 1, "daily", chart.FormatPNG, 20, 20); !isAppError(err, 400) {
+		t.Errorf("RenderEquityChart (too small): expected 400, got %v", err)
+	}
+
+	//--- Stored data that cannot be parsed is a server fault
+	if err := backend.WriteEquityData("john", 1, "weekly", []byte("corrupted")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenderEquityChart(c, 1, "weekly", chart.FormatPNG, 0, 0); !isAppError(err, 500) {
+		t.Errorf("RenderEquityChart (corrupted): expected 500, got %v", err)
+	}
+}
+
+//=============================================================================
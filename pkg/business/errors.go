@@ -0,0 +1,67 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"io/fs"
+	"net/http"
+)
+
+//=============================================================================
+
+func newAppError(code int, format string, params ...any) error {
+	return req.AppError{
+		Code   : code,
+		Message: fmt.Sprintf(format, params...),
+	}
+}
+
+//=============================================================================
+//=== Maps a missing file to a 404 error and everything else to a 500 one
+
+func convertError(err error, format string, params ...any) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return newAppError(http.StatusNotFound, format, params...)
+	}
+
+	return convertServerError(err)
+}
+
+//=============================================================================
+//=== Invalid paths are the caller's fault and keep their 400 error
+
+func convertServerError(err error) error {
+	if errors.Is(err, backend.ErrInvalidPath) {
+		return err
+	}
+
+	return req.NewServerErrorByError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,256 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/imaging"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+
+var imageExtensions = map[string]string{
+	".png" : imaging.FormatPNG,
+	".jpg" : imaging.FormatJPEG,
+	".jpeg": imaging.FormatJPEG,
+	".gif" : imaging.FormatGIF,
+	".webp": imaging.FormatWebP,
+}
+
+//=============================================================================
+
+func GetImages(c *auth.Context, id uint) ([]*ImageInfo, error) {
+	c.Log.Info("GetImages: Getting images for trading system", "id", id)
+
+	err := checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("GetImages: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	files, err := backend.GetImageFiles(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetImages: Cannot list images", "id", id, "error", err)
+		return nil, err
+	}
+
+	list := []*ImageInfo{}
+
+	for _, file := range files {
+		ii, err := getImageInfo(c, id, &file)
+		if err != nil {
+			c.Log.Error("GetImages: Cannot get image information", "id", id, "name", file.Name, "error", err)
+			return nil, err
+		}
+
+		if ii == nil {
+			c.Log.Warn("GetImages: Skipping file that is not a valid image", "id", id, "name", file.Name)
+			continue
+		}
+
+		list = append(list, ii)
+	}
+
+	c.Log.Info("GetImages: Operation complete", "id", id, "images", len(list))
+	return list, nil
+}
+
+//=============================================================================
+
+func GetImage(c *auth.Context, id uint, name string) (*ImageInfo, []byte, error) {
+	c.Log.Info("GetImage: Getting image for trading system", "id", id, "name", name)
+
+	err := validateImageName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := backend.GetImageInfo(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetImage: Cannot retrieve image", "id", id, "name", name, "error", err)
+		return nil, nil, convertError(err, "Image not found: %v", name)
+	}
+
+	data, err := backend.ReadImage(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetImage: Cannot read image", "id", id, "name", name, "error", err)
+		return nil, nil, convertError(err, "Image not found: %v", name)
+	}
+
+	info, err := imaging.Detect(data)
+	if err != nil {
+		c.Log.Error("GetImage: Stored image is not valid", "id", id, "name", name, "error", err)
+		return nil, nil, err
+	}
+
+	c.Log.Info("GetImage: Operation complete", "id", id, "name", name)
+
+	return newImageInfo(file, info), data, nil
+}
+
+//=============================================================================
+
+func UploadImage(c *auth.Context, id uint, name string, data []byte) (*ImageInfo, error) {
+	c.Log.Info("UploadImage: Uploading image for trading system", "id", id, "name", name, "size", len(data))
+
+	err := validateImageName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxSize := GetMaxImageSize(c); int64(len(data)) > maxSize {
+		c.Log.Error("UploadImage: Image is too big", "id", id, "name", name, "size", len(data), "maxSize", maxSize)
+		return nil, newAppError(http.StatusRequestEntityTooLarge, "Image exceeds the maximum size of %v bytes", maxSize)
+	}
+
+	info, err := imaging.Detect(data)
+	if err != nil {
+		c.Log.Error("UploadImage: Invalid image", "id", id, "name", name, "error", err)
+		return nil, newAppError(http.StatusBadRequest, "Unsupported or corrupted image (allowed are PNG, JPEG, WebP, GIF): %v", name)
+	}
+
+	if imageExtensions[strings.ToLower(path.Ext(name))] != info.Format {
+		return nil, newAppError(http.StatusBadRequest, "Image content (%v) does not match its name: %v", info.Format, name)
+	}
+
+	err = checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("UploadImage: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	im := &backend.ImageMetadata{
+		Format    : info.Format,
+		Width     : info.Width,
+		Height    : info.Height,
+		UploadedAt: time.Now().UTC(),
+		Author    : c.Session.Username,
+	}
+
+	err = backend.WriteImage(c.Session.Username, id, name, data, im)
+	if err != nil {
+		c.Log.Error("UploadImage: Cannot write image", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	file, err := backend.GetImageInfo(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("UploadImage: Cannot retrieve image", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	c.Log.Info("UploadImage: Operation complete", "id", id, "name", name, "width", info.Width, "height", info.Height)
+	return newImageInfo(file, info), nil
+}
+
+//=============================================================================
+
+func DeleteImage(c *auth.Context, id uint, name string) error {
+	c.Log.Info("DeleteImage: Deleting image for trading system", "id", id, "name", name)
+
+	err := validateImageName(name)
+	if err != nil {
+		return err
+	}
+
+	err = backend.DeleteImage(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("DeleteImage: Cannot delete image", "id", id, "name", name, "error", err)
+		return convertError(err, "Image not found: %v", name)
+	}
+
+	c.Log.Info("DeleteImage: Operation complete", "id", id, "name", name)
+	return nil
+}
+
+//=============================================================================
+//=== Private functions
+//=============================================================================
+
+func validateImageName(name string) error {
+	err := validateFileName(name)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := imageExtensions[strings.ToLower(path.Ext(name))]; !ok {
+		return newAppError(http.StatusBadRequest, "Unsupported image type (allowed are PNG, JPEG, WebP, GIF): %v", name)
+	}
+
+	return nil
+}
+
+//=============================================================================
+//=== Images stored without a sidecar are inspected on the fly. Returns nil
+//=== if the file cannot be decoded (e.g. corrupted or restored by hand)
+
+func getImageInfo(c *auth.Context, id uint, file *backend.FileInfo) (*ImageInfo, error) {
+	im, err := backend.ReadImageMetadata(c.Session.Username, id, file.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if im != nil {
+		return newImageInfo(file, &imaging.Info{
+			Format     : im.Format,
+			ContentType: imaging.GetContentType(im.Format),
+			Width      : im.Width,
+			Height     : im.Height,
+		}), nil
+	}
+
+	data, err := backend.ReadImage(c.Session.Username, id, file.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := imaging.Detect(data)
+	if err != nil {
+		return nil, nil
+	}
+
+	return newImageInfo(file, info), nil
+}
+
+//=============================================================================
+
+func newImageInfo(file *backend.FileInfo, info *imaging.Info) *ImageInfo {
+	return &ImageInfo{
+		Name       : file.Name,
+		Size       : file.Size,
+		ModTime    : file.ModTime,
+		Format     : info.Format,
+		ContentType: info.ContentType,
+		Width      : info.Width,
+		Height     : info.Height,
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,128 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"bytes"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"image"
+	"image/png"
+	"testing"
+)
+
+//=============================================================================
+
+func newPng(t *testing.T, w int, h int) []byte {
+	buf := &bytes.Buffer{}
+
+	err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, w, h)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+//=============================================================================
+
+func TestImages(t *testing.T) {
+	ms := setup(t)
+	c  := newContext("john")
+
+	info, err := UploadImage(c, 1, "entry.png", newPng(t, 64, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ContentType != "image/png" || info.Width != 64 || info.Height != 32 {
+		t.Errorf("UploadImage: got %+v", info)
+	}
+
+	//--- Images written without metadata are inspected when listed
+
+	err = ms.Put("john/1/"+ backend.Image +"/exit.png", newPng(t, 10, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//--- Files that cannot be decoded are skipped
+	err = ms.Put("john/1/"+ backend.Image +"/junk.png", []byte("not an image"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := GetImages(c, 1)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("GetImages: got %v, %v", list, err)
+	}
+	if list[0].Name != "entry.png" || list[1].Name != "exit.png" || list[1].Width != 10 || list[1].Height != 20 {
+		t.Errorf("GetImages: got %+v, %+v", list[0], list[1])
+	}
+
+	info, data, err := GetImage(c, 1, "entry.png")
+	if err != nil || info.Format != "png" || len(data) != int(info.Size) {
+		t.Errorf("GetImage: got %+v, %v", info, err)
+	}
+
+	if err = DeleteImage(c, 1, "entry.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = GetImage(c, 1, "entry.png"); !isAppError(err, 404) {
+		t.Errorf("GetImage (deleted): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
+
+func TestImages_Validation(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	tests := []struct {
+		name string
+		data []byte
+		code int
+	}{
+		{ "chart.svg",  []byte("<svg/>"),              400 },
+		{ "chart.png",  []byte("not an image"),        400 },
+		{ "chart.jpg",  newPng(t, 4, 4),               400 },
+		{ "../x.png",   newPng(t, 4, 4),               400 },
+		{ "chart.png",  make([]byte, DefaultMaxImageSize +1), 413 },
+	}
+
+	for _, test := range tests {
+		_, err := UploadImage(c, 1, test.name, test.data)
+		if !isAppError(err, test.code) {
+			t.Errorf("UploadImage(%s): expected %d, got %v", test.name, test.code, err)
+		}
+	}
+
+	_, err := UploadImage(newContext("jane"), 1, "chart.png", newPng(t, 4, 4))
+	if !isAppError(err, 404) {
+		t.Errorf("UploadImage (other user): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
@@ -24,6 +24,12 @@ THE SOFTWARE.
 
 package business
 
+import (
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/diff"
+	"time"
+)
+
 //=============================================================================
 
 type DocumentationRequest struct {
@@ -33,24 +39,199 @@ type DocumentationRequest struct {
 //=============================================================================
 
 type DocumentationResponse struct {
-	Id            uint   `json:"id"`
-	Name          string `json:"name"`
+	Id            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Documentation string    `json:"documentation"`
+	Revision      int       `json:"revision"`
+	ModTime       time.Time `json:"modTime"`
+	LastModified  time.Time `json:"-"`
+}
+
+//=============================================================================
+
+type DocumentationVersionResponse struct {
+	backend.Revision
 	Documentation string `json:"documentation"`
 }
 
 //=============================================================================
+//=== From/To are revision numbers, where CurrentDocumentation means the
+//=== documentation currently stored
+
+type DocumentationDiffResponse struct {
+	From    int          `json:"from"`
+	To      int          `json:"to"`
+	Hunks   []*diff.Hunk `json:"hunks"`
+	Unified string       `json:"unified"`
+}
+
+//=============================================================================
+//=== Metadata is optional and keyed by chart type, like Images
 
 type EquityRequest struct {
-	Username string            `json:"username"`
-	Images   map[string][]byte `json:"images"`
+	Username string                                  `json:"username"`
+	Images   map[string][]byte                       `json:"images"`
+	Metadata map[string]*backend.EquityChartMetadata `json:"metadata,omitempty"`
 }
 
 //=============================================================================
 
 func NewEquityRequest() *EquityRequest {
 	return &EquityRequest{
-		Images : map[string][]byte{},
+		Images  : map[string][]byte{},
+		Metadata: map[string]*backend.EquityChartMetadata{},
 	}
 }
 
 //=============================================================================
+
+type EquityChartSnapshot struct {
+	Timestamp   time.Time                    `json:"timestamp"`
+	Format      string                       `json:"format"`
+	ContentType string                       `json:"contentType"`
+	Size        int64                        `json:"size"`
+	Metadata    *backend.EquityChartMetadata `json:"metadata,omitempty"`
+}
+
+//=============================================================================
+//=== Status is one of the backend.ChartXXX values, or ChartSkipped when the
+//=== chart was never written
+
+const ChartSkipped = "skipped"
+
+type EquityResponse struct {
+	Charts []*EquityChartResult `json:"charts"`
+}
+
+//=============================================================================
+
+type EquityChartResult struct {
+	Type    string `json:"type"`
+	Format  string `json:"format,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+//=============================================================================
+
+type CodeFile struct {
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	ContentType string    `json:"contentType,omitempty"`
+	Revision    int       `json:"revision,omitempty"`
+}
+
+//=============================================================================
+
+type CodeRevisionsResponse struct {
+	Revisions  []*backend.Revision `json:"revisions"`
+	Deployment *backend.Deployment `json:"deployment"`
+}
+
+//=============================================================================
+
+type TagRequest struct {
+	Tag string `json:"tag" binding:"required"`
+}
+
+//=============================================================================
+
+type DeploymentRequest struct {
+	Revision int `json:"revision" binding:"required,min=1"`
+}
+
+//=============================================================================
+
+type ReportRequest struct {
+	Title       string `form:"title" binding:"required"`
+	PeriodFrom  string `form:"periodFrom"`
+	PeriodTo    string `form:"periodTo"`
+	GeneratedAt string `form:"generatedAt"`
+	Tool        string `form:"tool"`
+}
+
+//=============================================================================
+
+type ReportInfo struct {
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	ContentType string    `json:"contentType"`
+	backend.ReportMetadata
+}
+
+//=============================================================================
+
+type ImageInfo struct {
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	Format      string    `json:"format"`
+	ContentType string    `json:"contentType"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+}
+
+//=============================================================================
+
+type EquityChartInfo struct {
+	Type        string                       `json:"type"`
+	Format      string                       `json:"format"`
+	ContentType string                       `json:"contentType"`
+	Size        int64                        `json:"size"`
+	ModTime     time.Time                    `json:"modTime"`
+	Hash        string                       `json:"hash"`
+	Metadata    *backend.EquityChartMetadata `json:"metadata,omitempty"`
+}
+
+//=============================================================================
+
+type EquityChart struct {
+	Type        string
+	Format      string
+	ContentType string
+	Data        []byte
+	Hash        string
+	ModTime     time.Time
+	Placeholder bool
+}
+
+//=============================================================================
+
+type EquityDataRequest struct {
+	Username string            `json:"username"`
+	Format   string            `json:"format"`
+	Series   map[string]string `json:"series"`
+}
+
+//=============================================================================
+
+func NewEquityDataRequest() *EquityDataRequest {
+	return &EquityDataRequest{
+		Series : map[string]string{},
+	}
+}
+
+//=============================================================================
+
+type EquityDataFile struct {
+	Name        string
+	ContentType string
+	ModTime     time.Time
+}
+
+//=============================================================================
+
+type BatchChartResult struct {
+	Id          uint         `json:"id"`
+	Status      int          `json:"status"`
+	Message     string       `json:"message,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	Format      string       `json:"format,omitempty"`
+	Hash        string       `json:"hash,omitempty"`
+	Placeholder bool         `json:"placeholder,omitempty"`
+	Chart       *EquityChart `json:"-"`
+}
+
+//=============================================================================
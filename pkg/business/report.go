@@ -0,0 +1,299 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"bytes"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+
+type reportFormat struct {
+	contentType string
+	magic       []byte
+}
+
+//=============================================================================
+
+var reportFormats = map[string]*reportFormat{
+	".pdf" : { contentType: "application/pdf",          magic: []byte("%PDF-")          },
+	".html": { contentType: "text/html; charset=utf-8"                                  },
+	".htm" : { contentType: "text/html; charset=utf-8"                                  },
+	".csv" : { contentType: "text/csv; charset=utf-8"                                   },
+	".xlsx": { contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", magic: []byte("PK\x03\x04") },
+}
+
+//=============================================================================
+
+const dateFormat = time.DateOnly
+
+//=============================================================================
+
+func GetReports(c *auth.Context, id uint) ([]*ReportInfo, error) {
+	c.Log.Info("GetReports: Getting reports for trading system", "id", id)
+
+	err := checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("GetReports: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	files, err := backend.GetReportFiles(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetReports: Cannot list reports", "id", id, "error", err)
+		return nil, err
+	}
+
+	list := []*ReportInfo{}
+
+	for _, file := range files {
+		//--- Files not uploaded through the API (e.g. restored) can be anything
+		format := getReportFormat(file.Name)
+		if format == nil {
+			c.Log.Warn("GetReports: Skipping file with unsupported type", "id", id, "name", file.Name)
+			continue
+		}
+
+		rm, err := backend.ReadReportMetadata(c.Session.Username, id, file.Name)
+		if err != nil {
+			c.Log.Error("GetReports: Cannot read report metadata", "id", id, "name", file.Name, "error", err)
+			return nil, err
+		}
+
+		list = append(list, &ReportInfo{
+			Name          : file.Name,
+			Size          : file.Size,
+			ModTime       : file.ModTime,
+			ContentType   : format.contentType,
+			ReportMetadata: getReportMetadata(rm),
+		})
+	}
+
+	c.Log.Info("GetReports: Operation complete", "id", id, "reports", len(list))
+	return list, nil
+}
+
+//=============================================================================
+
+func GetReport(c *auth.Context, id uint, name string) (*ReportInfo, []byte, error) {
+	c.Log.Info("GetReport: Getting report for trading system", "id", id, "name", name)
+
+	err := validateReportName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := backend.GetReportInfo(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetReport: Cannot retrieve report", "id", id, "name", name, "error", err)
+		return nil, nil, convertError(err, "Report not found: %v", name)
+	}
+
+	data, err := backend.ReadReport(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetReport: Cannot read report", "id", id, "name", name, "error", err)
+		return nil, nil, convertError(err, "Report not found: %v", name)
+	}
+
+	rm, err := backend.ReadReportMetadata(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("GetReport: Cannot read report metadata", "id", id, "name", name, "error", err)
+		return nil, nil, err
+	}
+
+	c.Log.Info("GetReport: Operation complete", "id", id, "name", name)
+
+	return &ReportInfo{
+		Name          : name,
+		Size          : int64(len(data)),
+		ModTime       : info.ModTime,
+		ContentType   : getReportFormat(name).contentType,
+		ReportMetadata: getReportMetadata(rm),
+	}, data, nil
+}
+
+//=============================================================================
+
+func UploadReport(c *auth.Context, id uint, name string, rr *ReportRequest, data []byte) (*ReportInfo, error) {
+	c.Log.Info("UploadReport: Uploading report for trading system", "id", id, "name", name, "size", len(data))
+
+	err := validateReportName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxSize := GetMaxReportSize(c); int64(len(data)) > maxSize {
+		c.Log.Error("UploadReport: Report is too big", "id", id, "name", name, "size", len(data), "maxSize", maxSize)
+		return nil, newAppError(http.StatusRequestEntityTooLarge, "Report exceeds the maximum size of %v bytes", maxSize)
+	}
+
+	format := getReportFormat(name)
+	if !bytes.HasPrefix(data, format.magic) {
+		return nil, newAppError(http.StatusBadRequest, "Report content does not match its type: %v", name)
+	}
+
+	rm, err := buildReportMetadata(c, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("UploadReport: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	err = backend.WriteReport(c.Session.Username, id, name, data, rm)
+	if err != nil {
+		c.Log.Error("UploadReport: Cannot write report", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	info, err := backend.GetReportInfo(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("UploadReport: Cannot retrieve report", "id", id, "name", name, "error", err)
+		return nil, err
+	}
+
+	c.Log.Info("UploadReport: Operation complete", "id", id, "name", name)
+
+	return &ReportInfo{
+		Name          : name,
+		Size          : info.Size,
+		ModTime       : info.ModTime,
+		ContentType   : format.contentType,
+		ReportMetadata: *rm,
+	}, nil
+}
+
+//=============================================================================
+
+func DeleteReport(c *auth.Context, id uint, name string) error {
+	c.Log.Info("DeleteReport: Deleting report for trading system", "id", id, "name", name)
+
+	err := validateReportName(name)
+	if err != nil {
+		return err
+	}
+
+	err = backend.DeleteReport(c.Session.Username, id, name)
+	if err != nil {
+		c.Log.Error("DeleteReport: Cannot delete report", "id", id, "name", name, "error", err)
+		return convertError(err, "Report not found: %v", name)
+	}
+
+	c.Log.Info("DeleteReport: Operation complete", "id", id, "name", name)
+	return nil
+}
+
+//=============================================================================
+//=== Private functions
+//=============================================================================
+
+func validateReportName(name string) error {
+	err := validateFileName(name)
+	if err != nil {
+		return err
+	}
+
+	if getReportFormat(name) == nil {
+		return newAppError(http.StatusBadRequest, "Unsupported report type (allowed are PDF, HTML, XLSX, CSV): %v", name)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func getReportFormat(name string) *reportFormat {
+	return reportFormats[strings.ToLower(path.Ext(name))]
+}
+
+//=============================================================================
+//=== Reports without a sidecar have empty metadata
+
+func getReportMetadata(rm *backend.ReportMetadata) backend.ReportMetadata {
+	if rm == nil {
+		return backend.ReportMetadata{}
+	}
+
+	return *rm
+}
+
+//=============================================================================
+
+func buildReportMetadata(c *auth.Context, rr *ReportRequest) (*backend.ReportMetadata, error) {
+	rm := &backend.ReportMetadata{
+		Title      : strings.TrimSpace(rr.Title),
+		PeriodFrom : rr.PeriodFrom,
+		PeriodTo   : rr.PeriodTo,
+		GeneratedAt: time.Now().UTC(),
+		Tool       : strings.TrimSpace(rr.Tool),
+		Author     : c.Session.Username,
+	}
+
+	if rm.Title == "" {
+		return nil, newAppError(http.StatusBadRequest, "Missing report title")
+	}
+
+	var from, to time.Time
+	var err error
+
+	if rr.PeriodFrom != "" {
+		if from, err = time.Parse(dateFormat, rr.PeriodFrom); err != nil {
+			return nil, newAppError(http.StatusBadRequest, "Invalid 'periodFrom' date (expected YYYY-MM-DD): %v", rr.PeriodFrom)
+		}
+	}
+
+	if rr.PeriodTo != "" {
+		if to, err = time.Parse(dateFormat, rr.PeriodTo); err != nil {
+			return nil, newAppError(http.StatusBadRequest, "Invalid 'periodTo' date (expected YYYY-MM-DD): %v", rr.PeriodTo)
+		}
+	}
+
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, newAppError(http.StatusBadRequest, "Report period ends before it starts: %v - %v", rr.PeriodFrom, rr.PeriodTo)
+	}
+
+	if rr.GeneratedAt != "" {
+		rm.GeneratedAt, err = time.Parse(time.RFC3339, rr.GeneratedAt)
+		if err != nil {
+			rm.GeneratedAt, err = time.Parse(dateFormat, rr.GeneratedAt)
+			if err != nil {
+				return nil, newAppError(http.StatusBadRequest, "Invalid 'generatedAt' date: %v", rr.GeneratedAt)
+			}
+		}
+	}
+
+	return rm, nil
+}
+
+//=============================================================================
@@ -0,0 +1,126 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"testing"
+)
+
+//=============================================================================
+
+func TestReports(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	rr := &ReportRequest{
+		Title      : "Walk forward 2020-2025",
+		PeriodFrom : "2020-01-01",
+		PeriodTo   : "2025-12-31",
+		GeneratedAt: "2026-01-10T08:00:00Z",
+		Tool       : "MultiCharts",
+	}
+
+	info, err := UploadReport(c, 1, "wfa.pdf", rr, []byte("%PDF-1.7 ..."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ContentType != "application/pdf" || info.Title != rr.Title || info.Author != "john" || info.GeneratedAt.Year() != 2026 {
+		t.Errorf("UploadReport: got %+v", info)
+	}
+
+	if _, err = UploadReport(c, 1, "trades.csv", &ReportRequest{ Title: "Trades" }, []byte("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	//--- Files that did not come through the API, e.g. restored from a backup
+	for _, name := range []string{ "notes.txt", "summary.html" } {
+		if err = backend.WriteUserFile("john", "1/"+ backend.Report +"/"+ name, []byte("<html/>")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := GetReports(c, 1)
+	if err != nil || len(list) != 3 || list[0].Name != "summary.html" || list[0].Title != "" {
+		t.Fatalf("GetReports: got %v, %v", list, err)
+	}
+	list = list[1:]
+	if list[1].Name != "wfa.pdf" || list[1].PeriodTo != "2025-12-31" || list[1].Tool != "MultiCharts" {
+		t.Errorf("GetReports: bad metadata %+v", list[1])
+	}
+
+	info, data, err := GetReport(c, 1, "trades.csv")
+	if err != nil || string(data) != "a,b\n" || info.ContentType != "text/csv; charset=utf-8" {
+		t.Errorf("GetReport: got %+v, %q, %v", info, data, err)
+	}
+
+	if err = DeleteReport(c, 1, "wfa.pdf"); err != nil {
+		t.Fatal(err)
+	}
+	if err = DeleteReport(c, 1, "wfa.pdf"); !isAppError(err, 404) {
+		t.Errorf("DeleteReport (missing): expected 404, got %v", err)
+	}
+
+	list, err = GetReports(c, 1)
+	if err != nil || len(list) != 2 {
+		t.Errorf("GetReports after delete: got %v, %v", list, err)
+	}
+}
+
+//=============================================================================
+
+func TestReports_Validation(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	tests := []struct {
+		name string
+		rr   ReportRequest
+		data string
+		code int
+	}{
+		{ "report.exe",  ReportRequest{ Title: "x" },                                           "MZ",       400 },
+		{ "report.pdf",  ReportRequest{ Title: "x" },                                           "not pdf",  400 },
+		{ "report.xlsx", ReportRequest{ Title: "x" },                                           "not zip",  400 },
+		{ "report.pdf",  ReportRequest{ Title: " " },                                           "%PDF-1.4", 400 },
+		{ "report.pdf",  ReportRequest{ Title: "x", PeriodFrom: "01/01/2020" },                 "%PDF-1.4", 400 },
+		{ "report.pdf",  ReportRequest{ Title: "x", PeriodFrom: "2021-01-01", PeriodTo: "2020-01-01" }, "%PDF-1.4", 400 },
+		{ "report.pdf",  ReportRequest{ Title: "x", GeneratedAt: "yesterday" },                 "%PDF-1.4", 400 },
+	}
+
+	for _, test := range tests {
+		_, err := UploadReport(c, 1, test.name, &test.rr, []byte(test.data))
+		if !isAppError(err, test.code) {
+			t.Errorf("UploadReport(%s, %+v): expected %d, got %v", test.name, test.rr, test.code, err)
+		}
+	}
+
+	_, err := UploadReport(newContext("jane"), 1, "report.csv", &ReportRequest{ Title: "x" }, []byte("a"))
+	if !isAppError(err, 404) {
+		t.Errorf("UploadReport (other user): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,126 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+//=============================================================================
+//=== Service-role callers (e.g. the portfolio trader) write on behalf of the
+//=== user named in the request, so the request is checked against the owner
+//=== recorded in <username>/<id>/info.json, and the caller against the
+//=== configured client ids. Every write is audited.
+//=============================================================================
+
+const (
+	AuditAllowed = "allowed"
+	AuditDenied  = "denied"
+)
+
+//=============================================================================
+
+func checkServiceCaller(c *auth.Context, action string, username string, id uint) error {
+	clientId := getClientId(c.Token)
+
+	allowed := getStorageConfig(c).ServiceClients
+	if len(allowed) > 0 && !slices.Contains(allowed, clientId) {
+		audit(c, action, clientId, username, id, AuditDenied, "client not allowed")
+		return newAppError(http.StatusForbidden, "Service client not allowed: %v", clientId)
+	}
+
+	err := backend.CheckSegment(username)
+	if err != nil {
+		audit(c, action, clientId, username, id, AuditDenied, err.Error())
+		return convertServerError(err)
+	}
+
+	ts, err := backend.GetTradingSystemInfo(username, id)
+	if err != nil {
+		audit(c, action, clientId, username, id, AuditDenied, err.Error())
+		return convertError(err, "Trading system not found: %v", id)
+	}
+
+	if ts.Username != username || ts.Id != id {
+		audit(c, action, clientId, username, id, AuditDenied, "owner mismatch: "+ ts.Username)
+		return newAppError(http.StatusForbidden, "Trading system %v is not owned by %v", id, username)
+	}
+
+	audit(c, action, clientId, username, id, AuditAllowed, "")
+
+	return nil
+}
+
+//=============================================================================
+//=== The token has already been verified: only the claims are read
+
+func getClientId(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+
+	claims := struct {
+		Azp      string `json:"azp"`
+		ClientId string `json:"client_id"`
+	}{}
+
+	if json.Unmarshal(payload, &claims) != nil {
+		return ""
+	}
+
+	if claims.Azp != "" {
+		return claims.Azp
+	}
+
+	return claims.ClientId
+}
+
+//=============================================================================
+
+func audit(c *auth.Context, action string, clientId string, username string, id uint, outcome string, reason string) {
+	c.Log.Warn("Audit: Service write",
+		"action",  action,
+		"outcome", outcome,
+		"caller",  c.Session.Username,
+		"client",  clientId,
+		"owner",   username,
+		"id",      id,
+		"reason",  reason)
+}
+
+//=============================================================================
@@ -0,0 +1,134 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+func newToken(claims string) string {
+	return "e30."+ base64.RawURLEncoding.EncodeToString([]byte(claims)) +".signature"
+}
+
+//=============================================================================
+
+func TestGetClientId(t *testing.T) {
+	tests := map[string]string{
+		newToken(`{"azp":"portfolio-trader","client_id":"other"}`): "portfolio-trader",
+		newToken(`{"client_id":"portfolio-trader"}`)              : "portfolio-trader",
+		newToken(`{"sub":"john"}`)                                : "",
+		newToken(`not json`)                                      : "",
+		"not-a-token"                                             : "",
+		""                                                        : "",
+	}
+
+	for token, expected := range tests {
+		if clientId := getClientId(token); clientId != expected {
+			t.Errorf("getClientId(%q): expected %q, got %q", token, expected, clientId)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestCheckServiceCaller(t *testing.T) {
+	ms := setup(t)
+
+	//--- info.json that does not match its folder
+	err := ms.Put("john/2/info.json", []byte(`{"id":2,"username":"jane","name":"Moved"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//--- jane owns 3
+	if err = backend.AddTradingSystem(&backend.TradingSystem{ Id: 3, Username: "jane", Name: "Mean reversion" }); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := &bytes.Buffer{}
+	c := newContext("portfolio-trader")
+	c.Log    = slog.New(slog.NewTextHandler(logs, nil))
+	c.Token  = newToken(`{"azp":"portfolio-trader"}`)
+	c.Config = &app.Config{ Storage: app.Storage{ ServiceClients: []string{ "portfolio-trader" } } }
+
+	req := NewEquityRequest()
+	req.Images["daily"] = []byte("daily-png")
+
+	tests := []struct {
+		username string
+		id       uint
+		token    string
+		code     int
+	}{
+		{ "john", 1, c.Token,                             0   },
+		{ "jane", 1, c.Token,                             404 },
+		{ "john", 9, c.Token,                             404 },
+		{ "john", 2, c.Token,                             403 },
+		{ "jane", 2, c.Token,                             404 },
+		{ "jane", 3, c.Token,                             0   },
+		{ "john", 3, c.Token,                             404 },
+		{ "john", 1, newToken(`{"azp":"other-service"}`), 403 },
+		{ "john", 1, "",                                  403 },
+	}
+
+	for _, test := range tests {
+		c.Token      = test.token
+		req.Username = test.username
+
+		_, err := SetEquityCharts(c, test.id, req)
+		if test.code == 0 && err != nil || test.code != 0 && !isAppError(err, test.code) {
+			t.Errorf("SetEquityCharts(%s, %d): expected %d, got %v", test.username, test.id, test.code, err)
+		}
+
+		err = DeleteEquityCharts(c, test.id, req)
+		if test.code == 0 && err != nil || test.code != 0 && !isAppError(err, test.code) {
+			t.Errorf("DeleteEquityCharts(%s, %d): expected %d, got %v", test.username, test.id, test.code, err)
+		}
+	}
+
+	//--- Charts are never written in a folder owned by another user
+	if types, _ := backend.GetEquityChartTypes("john", 2); len(types) != 0 {
+		t.Errorf("SetEquityCharts: chart written for john/2: %v", types)
+	}
+
+	//--- Every Service write is audited, allowed or not
+
+	audit := logs.String()
+	if strings.Count(audit, "Audit: Service write") != 2 * len(tests) ||
+		!strings.Contains(audit, "outcome=allowed") || !strings.Contains(audit, "client=other-service") {
+		t.Errorf("checkServiceCaller: missing audit entries in\n%s", audit)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,169 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/imaging"
+	"net/http"
+)
+
+//=============================================================================
+
+const MaxResizeSize = imaging.MaxSize
+
+//=============================================================================
+
+type ResizeRequest struct {
+	Width  int
+	Height int
+	Fit    string
+}
+
+//=============================================================================
+
+func ResizeEquityChart(c *auth.Context, id uint, ec *EquityChart, rr *ResizeRequest) error {
+	err := validateResizeRequest(rr)
+	if err != nil {
+		return err
+	}
+
+	//--- Vector charts are scaled by the client
+	if ec.Format == backend.ChartFormatSVG {
+		return nil
+	}
+
+	//--- The placeholder is shared by everybody: it is not worth caching
+	cache := !ec.Placeholder
+
+	data, _, err := resize(c, id, backend.VariantEquityChart, ec.Type, ec.Data, ec.Hash, rr, cache)
+	if err != nil {
+		return err
+	}
+
+	ec.Data = data
+	ec.Hash = ComputeHash(data)
+
+	return nil
+}
+
+//=============================================================================
+
+func ResizeImage(c *auth.Context, id uint, ii *ImageInfo, data []byte, rr *ResizeRequest) (*ImageInfo, []byte, error) {
+	err := validateResizeRequest(rr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, info, err := resize(c, id, backend.VariantImage, ii.Name, data, ComputeHash(data), rr, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ImageInfo{
+		Name       : ii.Name,
+		Size       : int64(len(data)),
+		ModTime    : ii.ModTime,
+		Format     : info.Format,
+		ContentType: info.ContentType,
+		Width      : info.Width,
+		Height     : info.Height,
+	}, data, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func validateResizeRequest(rr *ResizeRequest) error {
+	if rr.Width < 0 || rr.Width > MaxResizeSize || rr.Height < 0 || rr.Height > MaxResizeSize {
+		return newAppError(http.StatusBadRequest, "Width and height must be between 0 and %v", MaxResizeSize)
+	}
+
+	if rr.Width == 0 && rr.Height == 0 {
+		return newAppError(http.StatusBadRequest, "At least one of width and height must be given")
+	}
+
+	switch rr.Fit {
+	case "":
+		rr.Fit = imaging.FitContain
+	case imaging.FitContain, imaging.FitCover, imaging.FitFill:
+	default:
+		return newAppError(http.StatusBadRequest, "Invalid fit (allowed are contain, cover, fill): %v", rr.Fit)
+	}
+
+	return nil
+}
+
+//=============================================================================
+//=== Variants are cached using the hash of the source, so a changed source
+//=== never returns an old variant. A failed cache write is not an error
+
+func resize(c *auth.Context, id uint, group string, source string, data []byte, hash string, rr *ResizeRequest, cache bool) ([]byte, *imaging.Info, error) {
+	key := fmt.Sprintf("%s-%dx%d-%s", hash, rr.Width, rr.Height, rr.Fit)
+
+	if cache {
+		variant, err := backend.ReadVariant(c.Session.Username, id, group, source, key)
+		if err == nil {
+			info, err := imaging.Detect(variant)
+			if err == nil {
+				return variant, info, nil
+			}
+		}
+	}
+
+	variant, _, err := imaging.Resize(data, rr.Width, rr.Height, rr.Fit)
+	if err != nil {
+		c.Log.Error("resize: Cannot resize image", "id", id, "source", source, "error", err)
+
+		if errors.Is(err, imaging.ErrNotResizable) || errors.Is(err, imaging.ErrTooLarge) {
+			return nil, nil, newAppError(http.StatusBadRequest, "Cannot resize %v: %v", source, err.Error())
+		}
+
+		return nil, nil, err
+	}
+
+	info, err := imaging.Detect(variant)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cache {
+		err = backend.WriteVariant(c.Session.Username, id, group, source, key, variant)
+		if err != nil {
+			c.Log.Warn("resize: Cannot cache resized image", "id", id, "source", source, "error", err)
+		}
+	}
+
+	return variant, info, nil
+}
+
+//=============================================================================
@@ -0,0 +1,124 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/imaging"
+	"testing"
+)
+
+//=============================================================================
+
+func TestResizeEquityChart(t *testing.T) {
+	ms := setup(t)
+	c  := newContext("john")
+
+	req := NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"] = newPng(t, 400, 200)
+
+	if _, err := SetEquityCharts(newContext("portfolio-trader"), 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	ec, err := GetEquityChart(c, 1, "daily", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := ec.Hash
+	if err = ResizeEquityChart(c, 1, ec, &ResizeRequest{ Width: 100 }); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := imaging.Detect(ec.Data)
+	if err != nil || info.Width != 100 || info.Height != 50 || ec.Hash == source {
+		t.Errorf("ResizeEquityChart: got %+v, %v", info, err)
+	}
+
+	cached, err := backend.ReadVariant("john", 1, backend.VariantEquityChart, "daily", source +"-100x0-contain")
+	if err != nil || string(cached) != string(ec.Data) {
+		t.Fatalf("ResizeEquityChart: variant not cached: %v", err)
+	}
+
+	//--- Overwriting the chart must drop its variants
+
+	req.Images["daily"] = newPng(t, 300, 300)
+	if _, err = SetEquityCharts(newContext("portfolio-trader"), 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ms.List("john/1/"+ backend.CacheDir +"/"+ backend.VariantEquityChart +"/daily"); err == nil {
+		t.Errorf("SetEquityCharts: variants not invalidated")
+	}
+
+	ec, _ = GetEquityChart(c, 1, "daily", nil, false)
+	if err = ResizeEquityChart(c, 1, ec, &ResizeRequest{ Width: 100, Height: 40, Fit: imaging.FitCover }); err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ = imaging.Detect(ec.Data)
+	if info.Width != 100 || info.Height != 40 {
+		t.Errorf("ResizeEquityChart (cover): got %+v", info)
+	}
+}
+
+//=============================================================================
+
+func TestResizeImage(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	if _, err := UploadImage(c, 1, "setup.png", newPng(t, 64, 64)); err != nil {
+		t.Fatal(err)
+	}
+
+	ii, data, err := GetImage(c, 1, "setup.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ii, data, err = ResizeImage(c, 1, ii, data, &ResizeRequest{ Height: 16 })
+	if err != nil || ii.Width != 16 || ii.Height != 16 || ii.Size != int64(len(data)) {
+		t.Errorf("ResizeImage: got %+v, %v", ii, err)
+	}
+
+	tests := []ResizeRequest{
+		{},
+		{ Width: -1 },
+		{ Width: MaxResizeSize +1 },
+		{ Width: 10, Fit: "stretch" },
+	}
+
+	for _, rr := range tests {
+		if _, _, err = ResizeImage(c, 1, ii, data, &rr); !isAppError(err, 400) {
+			t.Errorf("ResizeImage(%+v): expected 400, got %v", rr, err)
+		}
+	}
+}
+
+//=============================================================================
@@ -25,8 +25,16 @@ THE SOFTWARE.
 package business
 
 import (
+	"errors"
 	"github.com/bit-fever/core/auth"
 	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/diff"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 //=============================================================================
@@ -47,58 +55,423 @@ func GetDocumentation(c *auth.Context, id uint) (*DocumentationResponse, error)
 		return nil, err
 	}
 
+	var rev int
+	rev,err = backend.GetTradingSystemDocCurrentRevision(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetDocumentation: Cannot retrieve documentation revision for trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	var file *backend.FileInfo
+	file,err = backend.GetTradingSystemDocInfo(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetDocumentation: Cannot retrieve documentation file for trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	//--- The name comes from info.json, so a rename changes the response too
+	var infoFile *backend.FileInfo
+	infoFile,err = backend.GetTradingSystemInfoFile(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetDocumentation: Cannot retrieve info file for trading system", "id", id, "error", err)
+		return nil, err
+	}
+
 	c.Log.Info("GetDocumentation: Operation complete", "id", id)
 
 	return &DocumentationResponse{
 		Id           : id,
 		Name         : info.Name,
 		Documentation: doc,
+		Revision     : rev,
+		ModTime      : file.ModTime,
+		LastModified : latest(file.ModTime, infoFile.ModTime),
 	}, nil
 }
 
 //=============================================================================
+//=== expectedRev is the revision the client started editing from (or
+//=== backend.AnyRevision to overwrite unconditionally)
+
+func SetDocumentation(c *auth.Context, id uint, r *DocumentationRequest, expectedRev int) (*backend.Revision, error) {
+	c.Log.Info("SetDocumentation: Setting documentation for trading system", "id", id, "expectedRevision", expectedRev)
 
-func SetDocumentation(c *auth.Context, id uint, r *DocumentationRequest) error {
-	c.Log.Info("SetDocumentation: Setting documentation for trading system", "id", id)
-	err := backend.SetTradingSystemDoc(c.Session.Username, id, r.Documentation)
+	_, err := backend.GetTradingSystemInfo(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("SetDocumentation: Cannot retrieve info for trading system", "id", id, "error", err)
+		return nil, convertError(err, "Trading system not found: %v", id)
+	}
+
+	rev := &backend.Revision{
+		Author: c.Session.Username,
+	}
+
+	err = backend.SaveTradingSystemDoc(c.Session.Username, id, r.Documentation, rev, expectedRev)
+
+	if errors.Is(err, backend.ErrRevisionMismatch) {
+		c.Log.Info("SetDocumentation: Documentation was changed by someone else", "id", id, "expectedRevision", expectedRev)
+		return nil, newAppError(http.StatusPreconditionFailed, "Documentation has been modified in the meantime. Reload it and apply your changes again")
+	}
 
 	if err != nil {
 		c.Log.Info("SetDocumentation: Cannot store documentation for trading system", "id", id, "error", err)
-	} else {
-		c.Log.Info("SetDocumentation: Operation complete", "id", id)
+		return nil, err
 	}
 
-	return err
+	c.Log.Info("SetDocumentation: Operation complete", "id", id, "revision", rev.Revision)
+	return rev, nil
 }
 
 //=============================================================================
 
-func GetEquityChart(c *auth.Context, id uint, chartType string) ([]byte, error) {
-	data, err := backend.ReadEquityChart(c.Session.Username, id, chartType)
+func GetDocumentationVersions(c *auth.Context, id uint) ([]*backend.Revision, error) {
+	c.Log.Info("GetDocumentationVersions: Getting documentation versions for trading system", "id", id)
+
+	_, err := backend.GetTradingSystemInfo(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetDocumentationVersions: Cannot retrieve info for trading system", "id", id, "error", err)
+		return nil, convertError(err, "Trading system not found: %v", id)
+	}
 
+	list, err := backend.GetTradingSystemDocRevisions(c.Session.Username, id)
 	if err != nil {
-		return backend.GetDefaultEquityChart(), nil
+		c.Log.Error("GetDocumentationVersions: Cannot retrieve documentation versions", "id", id, "error", err)
+		return nil, err
 	}
 
-	return data, err
+	c.Log.Info("GetDocumentationVersions: Operation complete", "id", id, "versions", len(list))
+	return list, nil
 }
 
 //=============================================================================
-// Called by Portfolio trader
 
-func SetEquityCharts(c *auth.Context, id uint, r *EquityRequest) error {
+func GetDocumentationVersion(c *auth.Context, id uint, rev int) (*DocumentationVersionResponse, error) {
+	c.Log.Info("GetDocumentationVersion: Getting documentation version for trading system", "id", id, "revision", rev)
+
+	r, doc, err := backend.GetTradingSystemDocRevision(c.Session.Username, id, rev)
+	if err != nil {
+		c.Log.Error("GetDocumentationVersion: Cannot retrieve documentation version", "id", id, "revision", rev, "error", err)
+		return nil, convertError(err, "Documentation version not found: %v", rev)
+	}
+
+	c.Log.Info("GetDocumentationVersion: Operation complete", "id", id, "revision", rev)
+
+	return &DocumentationVersionResponse{
+		Revision     : *r,
+		Documentation: doc,
+	}, nil
+}
+
+//=============================================================================
+
+func RestoreDocumentationVersion(c *auth.Context, id uint, rev int) (*backend.Revision, error) {
+	c.Log.Info("RestoreDocumentationVersion: Restoring documentation version for trading system", "id", id, "revision", rev)
+
+	_, doc, err := backend.GetTradingSystemDocRevision(c.Session.Username, id, rev)
+	if err != nil {
+		c.Log.Error("RestoreDocumentationVersion: Cannot retrieve documentation version", "id", id, "revision", rev, "error", err)
+		return nil, convertError(err, "Documentation version not found: %v", rev)
+	}
+
+	r := &backend.Revision{
+		Author      : c.Session.Username,
+		RestoredFrom: rev,
+	}
+
+	err = backend.SaveTradingSystemDoc(c.Session.Username, id, doc, r, backend.AnyRevision)
+	if err != nil {
+		c.Log.Error("RestoreDocumentationVersion: Cannot store documentation", "id", id, "revision", rev, "error", err)
+		return nil, err
+	}
+
+	c.Log.Info("RestoreDocumentationVersion: Operation complete", "id", id, "revision", rev, "newRevision", r.Revision)
+	return r, nil
+}
+
+//=============================================================================
+
+const CurrentDocumentation = 0
+
+//=============================================================================
+
+func DiffDocumentation(c *auth.Context, id uint, from int, to int) (*DocumentationDiffResponse, error) {
+	c.Log.Info("DiffDocumentation: Comparing documentation revisions", "id", id, "from", from, "to", to)
+
+	oldDoc, oldName, err := getDocumentationRevision(c, id, from)
+	if err != nil {
+		c.Log.Error("DiffDocumentation: Cannot retrieve documentation", "id", id, "revision", from, "error", err)
+		return nil, err
+	}
+
+	newDoc, newName, err := getDocumentationRevision(c, id, to)
+	if err != nil {
+		c.Log.Error("DiffDocumentation: Cannot retrieve documentation", "id", id, "revision", to, "error", err)
+		return nil, err
+	}
+
+	hunks := diff.Compute(oldDoc, newDoc, diff.DefaultContext)
+
+	c.Log.Info("DiffDocumentation: Operation complete", "id", id, "from", from, "to", to, "hunks", len(hunks))
+
+	return &DocumentationDiffResponse{
+		From   : from,
+		To     : to,
+		Hunks  : hunks,
+		Unified: diff.Unified(hunks, oldName, newName),
+	}, nil
+}
+
+//=============================================================================
+//=== Returns the chart in the first available format, in the order given.
+//=== When fallback is set, a missing chart is replaced by the default
+//=== placeholder and the returned flag is true. I/O errors are never hidden
+
+func GetEquityChart(c *auth.Context, id uint, chartType string, formats []string, fallback bool) (*EquityChart, error) {
+	if len(formats) == 0 {
+		formats = backend.ChartFormats
+	}
+
+	for _, format := range formats {
+		data, err := backend.ReadEquityChart(c.Session.Username, id, chartType, format)
+
+		var file *backend.FileInfo
+		if err == nil {
+			file, err = backend.GetEquityChartInfo(c.Session.Username, id, chartType, format)
+		}
+
+		if err == nil {
+			return &EquityChart{
+				Type       : chartType,
+				Format     : format,
+				ContentType: chartContentTypes[format],
+				Data       : data,
+				Hash       : ComputeHash(data),
+				ModTime    : file.ModTime,
+			}, nil
+		}
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.Log.Error("GetEquityChart: Cannot read equity chart", "id", id, "type", chartType, "format", format, "error", err)
+			return nil, convertError(err, "Equity chart not found: %v", chartType)
+		}
+	}
+
+	if fallback {
+		data := backend.GetDefaultEquityChart()
+
+		return &EquityChart{
+			Type       : chartType,
+			Format     : backend.ChartFormatPNG,
+			ContentType: chartContentTypes[backend.ChartFormatPNG],
+			Data       : data,
+			Hash       : ComputeHash(data),
+			Placeholder: true,
+		}, nil
+	}
+
+	//--- The chart may exist in formats that the caller does not accept
+
+	types, err := backend.GetEquityChartTypes(c.Session.Username, id)
+	if err == nil {
+		for _, ect := range types {
+			if ect.Type == chartType {
+				return nil, newAppError(http.StatusNotAcceptable, "Equity chart %v is only available as: %v", chartType, strings.Join(ect.Formats, ", "))
+			}
+		}
+	}
+
+	return nil, newAppError(http.StatusNotFound, "Equity chart not found: %v", chartType)
+}
+
+//=============================================================================
+
+func GetEquityCharts(c *auth.Context, id uint) ([]*EquityChartInfo, error) {
+	c.Log.Info("GetEquityCharts: Getting equity charts for trading system", "id", id)
+
+	err := checkTradingSystem(c, id)
+	if err != nil {
+		c.Log.Error("GetEquityCharts: Cannot retrieve trading system", "id", id, "error", err)
+		return nil, err
+	}
+
+	files, err := backend.GetEquityChartFiles(c.Session.Username, id)
+	if err != nil {
+		c.Log.Error("GetEquityCharts: Cannot list equity charts", "id", id, "error", err)
+		return nil, err
+	}
+
+	list := []*EquityChartInfo{}
+
+	for _, file := range files {
+		data, err := backend.ReadEquityChart(c.Session.Username, id, file.Type, file.Format)
+		if err != nil {
+			c.Log.Error("GetEquityCharts: Cannot read equity chart", "id", id, "type", file.Type, "format", file.Format, "error", err)
+			return nil, err
+		}
+
+		ecm, err := backend.ReadEquityChartMetadata(c.Session.Username, id, file.Type, file.Format)
+		if err != nil {
+			c.Log.Error("GetEquityCharts: Cannot read equity chart metadata", "id", id, "type", file.Type, "format", file.Format, "error", err)
+			return nil, err
+		}
+
+		list = append(list, &EquityChartInfo{
+			Type       : file.Type,
+			Format     : file.Format,
+			ContentType: chartContentTypes[file.Format],
+			Size       : file.Size,
+			ModTime    : file.ModTime,
+			Hash       : ComputeHash(data),
+			Metadata   : ecm,
+		})
+	}
+
+	c.Log.Info("GetEquityCharts: Operation complete", "id", id, "charts", len(list))
+	return list, nil
+}
+
+//=============================================================================
+
+func GetEquityChartMetadata(c *auth.Context, id uint, chartType string, format string) (*EquityChartInfo, error) {
+	c.Log.Info("GetEquityChartMetadata: Getting equity chart metadata", "id", id, "type", chartType, "format", format)
+
+	err := validateChartType(chartType)
+	if err != nil {
+		return nil, err
+	}
+
+	formats := backend.ChartFormats
+	if format != "" {
+		if _, ok := chartContentTypes[format]; !ok {
+			return nil, newAppError(http.StatusBadRequest, "Invalid chart format: %v", format)
+		}
+		formats = []string{ format }
+	}
+
+	for _, format := range formats {
+		file, err := backend.GetEquityChartInfo(c.Session.Username, id, chartType, format)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			c.Log.Error("GetEquityChartMetadata: Cannot read equity chart", "id", id, "type", chartType, "format", format, "error", err)
+			return nil, convertError(err, "Equity chart not found: %v", chartType)
+		}
+
+		data, err := backend.ReadEquityChart(c.Session.Username, id, chartType, format)
+		if err != nil {
+			c.Log.Error("GetEquityChartMetadata: Cannot read equity chart", "id", id, "type", chartType, "format", format, "error", err)
+			return nil, convertError(err, "Equity chart not found: %v", chartType)
+		}
+
+		ecm, err := backend.ReadEquityChartMetadata(c.Session.Username, id, chartType, format)
+		if err != nil {
+			c.Log.Error("GetEquityChartMetadata: Cannot read equity chart metadata", "id", id, "type", chartType, "format", format, "error", err)
+			return nil, convertServerError(err)
+		}
+
+		return &EquityChartInfo{
+			Type       : chartType,
+			Format     : format,
+			ContentType: chartContentTypes[format],
+			Size       : file.Size,
+			ModTime    : file.ModTime,
+			Hash       : ComputeHash(data),
+			Metadata   : ecm,
+		}, nil
+	}
+
+	return nil, newAppError(http.StatusNotFound, "Equity chart not found: %v", chartType)
+}
+
+//=============================================================================
+// Called by Portfolio trader. The format of each chart is detected from its
+// content (PNG, SVG or WebP)
+
+func SetEquityCharts(c *auth.Context, id uint, r *EquityRequest) (*EquityResponse, error) {
 	c.Log.Info("SetEquityCharts: Setting equity charts for trading system", "id", id)
 
-	for chartType,data := range r.Images {
-		err := backend.WriteEquityChart(r.Username, id, data, chartType)
+	err := checkServiceCaller(c, "SetEquityCharts", r.Username, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+	for chartType := range r.Images {
+		types = append(types, chartType)
+	}
+	for chartType := range r.Metadata {
+		if _, ok := r.Images[chartType]; !ok {
+			types = append(types, chartType)
+		}
+	}
+	sort.Strings(types)
+
+	res    := &EquityResponse{}
+	charts := []*backend.ChartWrite{}
+
+	//--- Nothing is written if a chart type is invalid
+
+	var invalid error
+
+	for _, chartType := range types {
+		ecr := &EquityChartResult{ Type: chartType, Status: ChartSkipped }
+		res.Charts = append(res.Charts, ecr)
+
+		ecm := r.Metadata[chartType]
+
+		err := validateChartType(chartType)
+		if err == nil {
+			if _, ok := r.Images[chartType]; !ok {
+				err = newAppError(http.StatusBadRequest, "Metadata given for a missing chart: %v", chartType)
+			}
+		}
+		if err == nil && ecm != nil {
+			err = validateChartMetadata(chartType, ecm)
+		}
+
 		if err != nil {
-			c.Log.Info("SetEquityCharts: Can't write equity chart", "id", id, "error", err, "type", chartType)
-			return err
+			ecr.Status  = backend.ChartFailed
+			ecr.Message = err.Error()
+			invalid     = err
+			continue
 		}
+
+		if ecm != nil {
+			ecm.UploadedAt = time.Now()
+			ecm.Author     = c.Session.Username
+		}
+
+		data := r.Images[chartType]
+		ecr.Format = detectChartFormat(data)
+		charts = append(charts, &backend.ChartWrite{ Type: chartType, Format: ecr.Format, Data: data, Metadata: ecm })
 	}
 
-	c.Log.Info("SetEquityCharts: Equity charts set", "id", id)
-	return nil
+	if invalid != nil {
+		c.Log.Info("SetEquityCharts: Invalid equity charts", "id", id, "error", invalid)
+		return res, invalid
+	}
+
+	err = backend.WriteEquityCharts(r.Username, id, charts, GetChartHistory(c))
+
+	for i, cw := range charts {
+		ecr := res.Charts[i]
+		if cw.Status != backend.ChartPending {
+			ecr.Status = cw.Status
+		}
+		if cw.Error != nil {
+			ecr.Message = cw.Error.Error()
+		}
+	}
+
+	if err != nil {
+		c.Log.Info("SetEquityCharts: Can't write equity charts", "id", id, "error", err)
+		return res, convertServerError(err)
+	}
+
+	c.Log.Info("SetEquityCharts: Equity charts set", "id", id, "charts", len(charts))
+	return res, nil
 }
 
 //=============================================================================
@@ -107,14 +480,21 @@ func SetEquityCharts(c *auth.Context, id uint, r *EquityRequest) error {
 func DeleteEquityCharts(c *auth.Context, id uint, r *EquityRequest) error {
 	c.Log.Info("DeleteEquityCharts: Delete equity chart for trading system", "id", id, "username", r.Username)
 
+	err := checkServiceCaller(c, "DeleteEquityCharts", r.Username, id)
+	if err != nil {
+		return err
+	}
+
 	types,err := backend.GetEquityChartTypes(r.Username, id)
 	if err == nil {
 		for _, ct := range types {
-			err = backend.DeleteEquityChart(r.Username, id, ct)
+			for _, format := range ct.Formats {
+				err = backend.DeleteEquityChart(r.Username, id, ct.Type, format)
 
-			if err != nil {
-				c.Log.Error("DeleteEquityCharts: Cannot delete equity chart", "id", id, "username", r.Username, "error", err, "type", ct)
-				return err
+				if err != nil {
+					c.Log.Error("DeleteEquityCharts: Cannot delete equity chart", "id", id, "username", r.Username, "error", err, "type", ct.Type, "format", format)
+					return err
+				}
 			}
 		}
 
@@ -125,3 +505,48 @@ func DeleteEquityCharts(c *auth.Context, id uint, r *EquityRequest) error {
 }
 
 //=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func latest(t1 time.Time, t2 time.Time) time.Time {
+	if t1.After(t2) {
+		return t1
+	}
+
+	return t2
+}
+
+//=============================================================================
+
+func checkTradingSystem(c *auth.Context, id uint) error {
+	_, err := backend.GetTradingSystemInfo(c.Session.Username, id)
+	if err != nil {
+		return convertError(err, "Trading system not found: %v", id)
+	}
+
+	return nil
+}
+
+//=============================================================================
+
+func getDocumentationRevision(c *auth.Context, id uint, rev int) (string, string, error) {
+	if rev == CurrentDocumentation {
+		doc, err := backend.GetTradingSystemDoc(c.Session.Username, id)
+		if err != nil {
+			return "", "", convertError(err, "Trading system not found: %v", id)
+		}
+
+		return doc, backend.DocFile, nil
+	}
+
+	_, doc, err := backend.GetTradingSystemDocRevision(c.Session.Username, id, rev)
+	if err != nil {
+		return "", "", convertError(err, "Documentation version not found: %v", rev)
+	}
+
+	return doc, backend.DocFile +"@"+ strconv.Itoa(rev), nil
+}
+
+//=============================================================================
@@ -0,0 +1,531 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package business
+
+import (
+	"errors"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+var defaultChart = []byte("default-chart")
+
+//=============================================================================
+
+func setup(t *testing.T) *backend.MemoryStore {
+	ms := backend.InitMemoryStorage(defaultChart)
+
+	err := backend.AddTradingSystem(&backend.TradingSystem{ Id: 1, Username: "john", Name: "Breakout" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ms
+}
+
+//=============================================================================
+
+func newContext(username string) *auth.Context {
+	return &auth.Context{
+		Session: &auth.UserSession{ Username: username },
+		Log    : slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+//=============================================================================
+
+func TestDocumentation(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	res, err := GetDocumentation(c, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Id != 1 || res.Name != "Breakout" || res.Documentation != "" {
+		t.Errorf("GetDocumentation: unexpected response %+v", res)
+	}
+
+	_, err = SetDocumentation(c, 1, &DocumentationRequest{ Documentation: "Trend following" }, backend.AnyRevision)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = GetDocumentation(c, 1)
+	if err != nil || res.Documentation != "Trend following" {
+		t.Errorf("GetDocumentation: got %+v, %v", res, err)
+	}
+}
+
+//=============================================================================
+
+func TestDocumentation_OtherUser(t *testing.T) {
+	setup(t)
+
+	_, err := GetDocumentation(newContext("jane"), 1)
+	if err == nil {
+		t.Errorf("GetDocumentation: documentation of another user must not be visible")
+	}
+}
+
+//=============================================================================
+
+func TestEquityCharts(t *testing.T) {
+	setup(t)
+	c := newContext("portfolio-trader")
+
+	req := NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"]  = []byte("daily-png")
+	req.Images["weekly"] = []byte("weekly-png")
+
+	if _, err := SetEquityCharts(c, 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		chartType   string
+		expected    string
+		placeholder bool
+	}{
+		{ "daily",   "daily-png",     false },
+		{ "weekly",  "weekly-png",    false },
+		{ "monthly", "default-chart", true  },
+	}
+
+	for _, test := range tests {
+		ec, err := GetEquityChart(newContext("john"), 1, test.chartType, nil, true)
+		if err != nil || string(ec.Data) != test.expected || ec.Placeholder != test.placeholder || ec.Hash != ComputeHash(ec.Data) {
+			t.Errorf("GetEquityChart(%s): got %+v, %v", test.chartType, ec, err)
+		}
+	}
+
+	if _, err := GetEquityChart(newContext("john"), 1, "monthly", nil, false); !isAppError(err, 404) {
+		t.Errorf("GetEquityChart (missing, no fallback): expected 404, got %v", err)
+	}
+
+	if err := DeleteEquityCharts(c, 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	types, err := backend.GetEquityChartTypes("john", 1)
+	if err != nil || len(types) != 0 {
+		t.Errorf("DeleteEquityCharts: charts still present: %v, %v", types, err)
+	}
+}
+
+//=============================================================================
+
+func TestDocumentationVersions(t *testing.T) {
+	setup(t)
+	john := newContext("john")
+	jane := newContext("jane")
+
+	for _, doc := range []string{ "first", "second", "third" } {
+		if _, err := SetDocumentation(john, 1, &DocumentationRequest{ Documentation: doc }, backend.AnyRevision); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := GetDocumentationVersions(john, 1)
+	if err != nil || len(list) != 3 {
+		t.Fatalf("GetDocumentationVersions: got %v, %v", list, err)
+	}
+	if list[2].Revision != 3 || list[2].Author != "john" || list[2].Size != len("third") {
+		t.Errorf("GetDocumentationVersions: bad last revision %+v", list[2])
+	}
+
+	ver, err := GetDocumentationVersion(john, 1, 2)
+	if err != nil || ver.Documentation != "second" || ver.Revision.Revision != 2 {
+		t.Errorf("GetDocumentationVersion: got %+v, %v", ver, err)
+	}
+
+	r, err := RestoreDocumentationVersion(john, 1, 1)
+	if err != nil || r.Revision != 4 || r.RestoredFrom != 1 {
+		t.Fatalf("RestoreDocumentationVersion: got %+v, %v", r, err)
+	}
+
+	res, err := GetDocumentation(john, 1)
+	if err != nil || res.Documentation != "first" {
+		t.Errorf("GetDocumentation after restore: got %+v, %v", res, err)
+	}
+
+	if _, err = GetDocumentationVersion(john, 1, 9); !isAppError(err, 404) {
+		t.Errorf("GetDocumentationVersion (missing): expected 404, got %v", err)
+	}
+	if _, err = GetDocumentationVersions(jane, 1); !isAppError(err, 404) {
+		t.Errorf("GetDocumentationVersions (other user): expected 404, got %v", err)
+	}
+	if _, err = RestoreDocumentationVersion(jane, 1, 1); !isAppError(err, 404) {
+		t.Errorf("RestoreDocumentationVersion (other user): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
+//=== Documentation stored before history existed must not be lost
+
+func TestDocumentationVersions_Baseline(t *testing.T) {
+	setup(t)
+	john := newContext("john")
+
+	if err := backend.SetTradingSystemDoc("john", 1, "written before history"); err != nil {
+		t.Fatal(err)
+	}
+
+	rev, err := SetDocumentation(john, 1, &DocumentationRequest{ Documentation: "first save" }, 0)
+	if err != nil || rev.Revision != 2 {
+		t.Fatalf("SetDocumentation: got %+v, %v", rev, err)
+	}
+
+	ver, err := GetDocumentationVersion(john, 1, 1)
+	if err != nil || ver.Documentation != "written before history" || ver.Revision.Author != backend.UnknownAuthor {
+		t.Errorf("GetDocumentationVersion (baseline): got %+v, %v", ver, err)
+	}
+
+	//--- Empty documentation, as written by AddTradingSystem, has no baseline
+	setup(t)
+
+	if rev, err = SetDocumentation(john, 1, &DocumentationRequest{ Documentation: "first save" }, 0); err != nil || rev.Revision != 1 {
+		t.Errorf("SetDocumentation (empty): got %+v, %v", rev, err)
+	}
+}
+
+//=============================================================================
+
+func TestDocumentation_Concurrency(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	res, err := GetDocumentation(c, 1)
+	if err != nil || res.Revision != 0 {
+		t.Fatalf("GetDocumentation: got %+v, %v", res, err)
+	}
+
+	//--- Two editors start from the same revision: the second one must fail
+
+	rev, err := SetDocumentation(c, 1, &DocumentationRequest{ Documentation: "tab 1" }, res.Revision)
+	if err != nil || rev.Revision != 1 {
+		t.Fatalf("SetDocumentation (tab 1): got %+v, %v", rev, err)
+	}
+
+	_, err = SetDocumentation(c, 1, &DocumentationRequest{ Documentation: "tab 2" }, res.Revision)
+	if !isAppError(err, 412) {
+		t.Fatalf("SetDocumentation (tab 2): expected 412, got %v", err)
+	}
+
+	res, err = GetDocumentation(c, 1)
+	if err != nil || res.Documentation != "tab 1" || res.Revision != 1 {
+		t.Errorf("GetDocumentation: got %+v, %v", res, err)
+	}
+
+	_, err = SetDocumentation(newContext("jane"), 1, &DocumentationRequest{ Documentation: "x" }, backend.AnyRevision)
+	if !isAppError(err, 404) {
+		t.Errorf("SetDocumentation (other user): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
+
+func isAppError(err error, code int) bool {
+	var ae req.AppError
+	return errors.As(err, &ae) && ae.Code == code
+}
+
+//=============================================================================
+
+func TestDiffDocumentation(t *testing.T) {
+	setup(t)
+	c := newContext("john")
+
+	for _, doc := range []string{ "entry\nexit\n", "entry\nstop loss\nexit\n" } {
+		if _, err := SetDocumentation(c, 1, &DocumentationRequest{ Documentation: doc }, backend.AnyRevision); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := DiffDocumentation(c, 1, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "--- documentation.txt@1\n+++ documentation.txt@2\n@@ -1,2 +1,3 @@\n entry\n+stop loss\n exit\n"
+	if res.Unified != expected || len(res.Hunks) != 1 {
+		t.Errorf("DiffDocumentation: got %q", res.Unified)
+	}
+
+	res, err = DiffDocumentation(c, 1, 2, CurrentDocumentation)
+	if err != nil || len(res.Hunks) != 0 {
+		t.Errorf("DiffDocumentation (current): got %+v, %v", res, err)
+	}
+
+	if _, err = DiffDocumentation(c, 1, 1, 7); !isAppError(err, 404) {
+		t.Errorf("DiffDocumentation (missing): expected 404, got %v", err)
+	}
+}
+
+//=============================================================================
+
+func TestEquityCharts_Formats(t *testing.T) {
+	setup(t)
+	service := newContext("portfolio-trader")
+	c       := newContext("john")
+
+	svg := `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`
+
+	//--- Each format is stored next to the others
+	for _, data := range []string{ "daily-png", svg } {
+		req := NewEquityRequest()
+		req.Username = "john"
+		req.Images["daily"] = []byte(data)
+
+		if _, err := SetEquityCharts(service, 1, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	types, err := backend.GetEquityChartTypes("john", 1)
+	if err != nil || len(types) != 1 || types[0].Type != "daily" || strings.Join(types[0].Formats, ",") != "png,svg" {
+		t.Fatalf("GetEquityChartTypes: got %v, %v", types, err)
+	}
+
+	tests := []struct {
+		formats []string
+		format  string
+		code    int
+	}{
+		{ nil,                                                         backend.ChartFormatPNG, 0   },
+		{ []string{ backend.ChartFormatSVG },                          backend.ChartFormatSVG, 0   },
+		{ []string{ backend.ChartFormatWebP, backend.ChartFormatSVG }, backend.ChartFormatSVG, 0   },
+		{ []string{ backend.ChartFormatWebP },                         "",                     406 },
+	}
+
+	for _, test := range tests {
+		ec, err := GetEquityChart(c, 1, "daily", test.formats, false)
+		if test.code != 0 {
+			if !isAppError(err, test.code) {
+				t.Errorf("GetEquityChart(%v): expected %d, got %v", test.formats, test.code, err)
+			}
+		} else if err != nil || ec.Format != test.format || ec.ContentType != chartContentTypes[test.format] {
+			t.Errorf("GetEquityChart(%v): got %+v, %v", test.formats, ec, err)
+		}
+	}
+
+	list, err := GetEquityCharts(c, 1)
+	if err != nil || len(list) != 2 || list[1].ContentType != "image/svg+xml" {
+		t.Errorf("GetEquityCharts: got %v, %v", list, err)
+	}
+
+	//--- A new PNG replaces only the PNG file
+	req := NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"] = []byte("daily-png-v2")
+
+	if _, err = SetEquityCharts(service, 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	for format, expected := range map[string]string{ backend.ChartFormatPNG: "daily-png-v2", backend.ChartFormatSVG: svg } {
+		ec, err := GetEquityChart(c, 1, "daily", []string{ format }, false)
+		if err != nil || ec.Format != format || string(ec.Data) != expected {
+			t.Errorf("GetEquityChart(%s): got %+v, %v", format, ec, err)
+		}
+	}
+
+	if err = DeleteEquityCharts(service, 1, &EquityRequest{ Username: "john" }); err != nil {
+		t.Fatal(err)
+	}
+
+	types, _ = backend.GetEquityChartTypes("john", 1)
+	if len(types) != 0 {
+		t.Errorf("DeleteEquityCharts: charts still present: %v", types)
+	}
+}
+
+//=============================================================================
+
+func TestEquityCharts_Atomic(t *testing.T) {
+	ms := setup(t)
+	c  := newContext("portfolio-trader")
+
+	req := NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"] = []byte("old-daily")
+
+	if _, err := SetEquityCharts(c, 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	//--- A folder in place of the weekly chart makes its write fail
+
+	if err := ms.MakeDir("john/1/weekly-equity-chart.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	req.Images["daily"]   = []byte("new-daily")
+	req.Images["monthly"] = []byte("new-monthly")
+	req.Images["weekly"]  = []byte("new-weekly")
+
+	res, err := SetEquityCharts(c, 1, req)
+	if !isAppError(err, 500) || res == nil || len(res.Charts) != 3 {
+		t.Fatalf("SetEquityCharts: expected 500 with results, got %+v, %v", res, err)
+	}
+
+	expected := []string{ backend.ChartRolledBack, backend.ChartRolledBack, backend.ChartFailed }
+	for i, ecr := range res.Charts {
+		if ecr.Status != expected[i] {
+			t.Errorf("SetEquityCharts: %s has status %s, expected %s", ecr.Type, ecr.Status, expected[i])
+		}
+	}
+
+	if data, _ := backend.ReadEquityChart("john", 1, "daily", backend.ChartFormatPNG); string(data) != "old-daily" {
+		t.Errorf("SetEquityCharts: daily chart not restored, got %q", data)
+	}
+	if _, err = backend.ReadEquityChart("john", 1, "monthly", backend.ChartFormatPNG); err == nil {
+		t.Errorf("SetEquityCharts: monthly chart not removed")
+	}
+	if files, _ := ms.List("john/1/"+ backend.StagingDir); len(files) != 0 {
+		t.Errorf("SetEquityCharts: staging area not cleaned, got %v", files)
+	}
+
+	//--- An invalid type rejects the whole request
+
+	req = NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"]     = []byte("new-daily")
+	req.Images["bad type!"] = []byte("new-bad")
+
+	res, err = SetEquityCharts(c, 1, req)
+	if !isAppError(err, 400) || res.Charts[0].Status != backend.ChartFailed || res.Charts[1].Status != ChartSkipped {
+		t.Errorf("SetEquityCharts: expected 400 with results, got %+v, %v", res, err)
+	}
+
+	if data, _ := backend.ReadEquityChart("john", 1, "daily", backend.ChartFormatPNG); string(data) != "old-daily" {
+		t.Errorf("SetEquityCharts: daily chart written by a rejected request, got %q", data)
+	}
+}
+
+//=============================================================================
+
+func TestEquityCharts_Metadata(t *testing.T) {
+	ms := setup(t)
+	c  := newContext("portfolio-trader")
+
+	from   := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to     := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	trades := 152
+
+	req := NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"]   = []byte("daily-png")
+	req.Images["weekly"]  = []byte("weekly-png")
+	req.Metadata["daily"] = &backend.EquityChartMetadata{
+		GeneratedAt    : &to,
+		DataFrom       : &from,
+		DataTo         : &to,
+		Trades         : &trades,
+		ProducerVersion: "1.4.2",
+		Author         : "someone-else",
+	}
+
+	if _, err := SetEquityCharts(c, 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	john := newContext("john")
+
+	eci, err := GetEquityChartMetadata(john, 1, "daily", "")
+	if err != nil || eci.Format != backend.ChartFormatPNG || eci.Metadata == nil {
+		t.Fatalf("GetEquityChartMetadata: got %+v, %v", eci, err)
+	}
+
+	ecm := eci.Metadata
+	if !ecm.DataFrom.Equal(from) || *ecm.Trades != trades || ecm.ProducerVersion != "1.4.2" || ecm.Author != "portfolio-trader" || ecm.UploadedAt.IsZero() {
+		t.Errorf("GetEquityChartMetadata: bad metadata %+v", ecm)
+	}
+
+	if eci, err = GetEquityChartMetadata(john, 1, "weekly", ""); err != nil || eci.Metadata != nil {
+		t.Errorf("GetEquityChartMetadata (weekly): got %+v, %v", eci, err)
+	}
+
+	if _, err = GetEquityChartMetadata(john, 1, "daily", backend.ChartFormatSVG); !isAppError(err, 404) {
+		t.Errorf("GetEquityChartMetadata (svg): expected 404, got %v", err)
+	}
+	if _, err = GetEquityChartMetadata(newContext("jane"), 1, "daily", ""); !isAppError(err, 404) {
+		t.Errorf("GetEquityChartMetadata (other user): expected 404, got %v", err)
+	}
+
+	//--- A chart written without metadata loses the old one
+
+	req = NewEquityRequest()
+	req.Username = "john"
+	req.Images["daily"] = []byte("new-daily-png")
+
+	if _, err = SetEquityCharts(c, 1, req); err != nil {
+		t.Fatal(err)
+	}
+	if eci, err = GetEquityChartMetadata(john, 1, "daily", ""); err != nil || eci.Metadata != nil {
+		t.Errorf("GetEquityChartMetadata: stale metadata %+v, %v", eci, err)
+	}
+
+	//--- Invalid metadata
+
+	negative := -1
+	invalid  := []map[string]*backend.EquityChartMetadata{
+		{ "daily"  : { DataFrom: &to, DataTo: &from } },
+		{ "daily"  : { Trades: &negative } },
+		{ "monthly": { Trades: &trades } },
+	}
+
+	for _, metadata := range invalid {
+		req.Metadata = metadata
+		if _, err = SetEquityCharts(c, 1, req); !isAppError(err, 400) {
+			t.Errorf("SetEquityCharts: expected 400 for %v, got %v", metadata, err)
+		}
+	}
+
+	//--- Deleting the chart removes its sidecar
+
+	req.Metadata = map[string]*backend.EquityChartMetadata{ "daily": { Trades: &trades } }
+	if _, err = SetEquityCharts(c, 1, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = backend.DeleteEquityChart("john", 1, "daily", backend.ChartFormatPNG); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ms.Get("john/1/daily-equity-chart.png"+ backend.MetadataSuffix); err == nil {
+		t.Errorf("DeleteEquityChart: metadata not removed")
+	}
+}
+
+//=============================================================================
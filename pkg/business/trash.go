@@ -0,0 +1,72 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package business
+
+import (
+	"errors"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"net/http"
+)
+
+//=============================================================================
+//=== Administration of the trash, where deleted trading systems are kept
+//=============================================================================
+
+func GetTrash(c *auth.Context, username string) ([]*backend.TrashEntry, error) {
+	c.Log.Info("GetTrash: Getting trashed trading systems", "owner", username)
+
+	list, err := backend.GetTrashEntries(username)
+	if err != nil {
+		c.Log.Error("GetTrash: Cannot list the trash", "owner", username, "error", err)
+		return nil, convertServerError(err)
+	}
+
+	c.Log.Info("GetTrash: Operation complete", "owner", username, "entries", len(list))
+	return list, nil
+}
+
+//=============================================================================
+
+func RestoreTrash(c *auth.Context, username string, entry string) (*backend.TrashEntry, error) {
+	c.Log.Info("RestoreTrash: Restoring trading system", "owner", username, "entry", entry)
+
+	te, err := backend.RestoreTradingSystem(username, entry)
+	if err != nil {
+		c.Log.Error("RestoreTrash: Cannot restore trading system", "owner", username, "entry", entry, "error", err)
+
+		if errors.Is(err, backend.ErrTradingSystemExists) {
+			return nil, newAppError(http.StatusConflict, "Trading system of trash entry %v already exists", entry)
+		}
+
+		return nil, convertError(err, "Trash entry not found: %v", entry)
+	}
+
+	c.Log.Info("RestoreTrash: Trading system restored", "owner", username, "id", te.Id, "name", te.Name)
+	return te, nil
+}
+
+//=============================================================================
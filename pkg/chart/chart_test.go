@@ -0,0 +1,282 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+//=============================================================================
+
+const sampleCSV = `timestamp,equity,drawdown
+2024-01-01,10000,0
+2024-01-02,10250.5,0
+2024-01-03,9900,-350.5
+2024-01-04,10400,0
+`
+
+//=============================================================================
+
+func TestParse(t *testing.T) {
+	points, err := Parse([]byte(sampleCSV), FormatCSV)
+	if err != nil || len(points) != 4 {
+		t.Fatalf("Parse (csv): got %v, %v", points, err)
+	}
+	if points[2].Equity != 9900 || points[2].Drawdown != -350.5 || points[3].Time.Day() != 4 {
+		t.Errorf("Parse (csv): bad point %+v", points[2])
+	}
+
+	//--- Drawdown computed from the equity when missing
+	points, err = Parse([]byte("timestamp,equity\n2024-01-01T10:00:00Z,100\n2024-01-02T10:00:00Z,80\n2024-01-03T10:00:00Z,120\n"), FormatCSV)
+	if err != nil || points[1].Drawdown != -20 || points[2].Drawdown != 0 {
+		t.Errorf("Parse (csv, no drawdown): got %+v, %v", points, err)
+	}
+
+	points, err = Parse([]byte(`[{"time":"2024-01-01T00:00:00Z","equity":1,"drawdown":0}]`), FormatJSON)
+	if err != nil || len(points) != 1 {
+		t.Errorf("Parse (json): got %v, %v", points, err)
+	}
+
+	back, err := Parse(ToCSV(points), FormatCSV)
+	if err != nil || len(back) != 1 || !back[0].Time.Equal(points[0].Time) {
+		t.Errorf("ToCSV: round trip failed: %v, %v", back, err)
+	}
+}
+
+//=============================================================================
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		data   string
+		format string
+	}{
+		{ "",                                             FormatCSV  },
+		{ "time,value\n2024-01-01,1\n",                   FormatCSV  },
+		{ "timestamp,equity\n01/01/2024,1\n",             FormatCSV  },
+		{ "timestamp,equity\n2024-01-01,abc\n",           FormatCSV  },
+		{ "timestamp,equity,drawdown\n2024-01-01,1,5\n",  FormatCSV  },
+		{ "timestamp,equity\n2024-01-02,1\n2024-01-01,1\n", FormatCSV },
+		{ "[]",                                           FormatJSON },
+		{ "{",                                            FormatJSON },
+		{ "[]",                                           "xml"      },
+	}
+
+	for _, test := range tests {
+		if _, err := Parse([]byte(test.data), test.format); err == nil {
+			t.Errorf("Parse(%q, %s): expected an error", test.data, test.format)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRender(t *testing.T) {
+	points, _ := Parse([]byte(sampleCSV), FormatCSV)
+
+	data, err := Render(points, FormatPNG, 320, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil || img.Bounds().Dx() != 320 || img.Bounds().Dy() != 200 {
+		t.Fatalf("Render (png): got %v, %v", img.Bounds(), err)
+	}
+
+	data, err = Render(points, FormatSVG, 320, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		if _, err = d.Token(); err != nil {
+			break
+		}
+	}
+	if err.Error() != "EOF" || !strings.Contains(string(data), "2024-01-04") {
+		t.Errorf("Render (svg): invalid document: %v", err)
+	}
+
+	if _, err = Render(points, FormatPNG, 20, 20); err == nil {
+		t.Errorf("Render: expected an error for a tiny chart")
+	}
+}
+
+//=============================================================================
+//=== Rendered charts are compared with the files in testdata. After a wanted
+//=== change of the output, run the tests with -update and review the images
+
+func TestRender_Golden(t *testing.T) {
+	points, _ := Parse([]byte(sampleCSV), FormatCSV)
+	single, _ := Parse([]byte("timestamp,equity,drawdown\n2024-01-01,10000,-150\n"), FormatCSV)
+
+	tests := []struct {
+		name   string
+		points []Point
+	}{
+		{ "axes",         points },
+		{ "empty",        nil    },
+		{ "single-point", single },
+	}
+
+	for _, test := range tests {
+		for _, format := range []string{ FormatPNG, FormatSVG } {
+			data, err := Render(test.points, format, 320, 200)
+			if err != nil {
+				t.Errorf("Render(%s, %s): %v", test.name, format, err)
+				continue
+			}
+
+			golden := filepath.Join("testdata", test.name +"."+ format)
+
+			if *update {
+				if err = os.WriteFile(golden, data, 0644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !sameImage(t, format, data, expected) {
+				t.Errorf("Render(%s, %s): output differs from %s", test.name, format, golden)
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRender_Content(t *testing.T) {
+	points, _ := Parse([]byte(sampleCSV), FormatCSV)
+
+	svg, _ := Render(points, FormatSVG, 320, 200)
+	for _, text := range []string{ ">2024-01-01<", ">2024-01-04<", ">0<", "<polyline", "<path" } {
+		if !strings.Contains(string(svg), text) {
+			t.Errorf("Render (axes): %q missing", text)
+		}
+	}
+
+	svg, _ = Render(nil, FormatSVG, 320, 200)
+	if strings.Contains(string(svg), "<polyline") || strings.Contains(string(svg), "<circle") || !strings.Contains(string(svg), "<line") {
+		t.Errorf("Render (empty): expected the bare grid, got %s", svg)
+	}
+
+	data, _ := Render(nil, FormatPNG, 320, 200)
+	img, _  := png.Decode(bytes.NewReader(data))
+	if countColor(img, colorEquity.R, colorEquity.G, colorEquity.B) != 0 {
+		t.Errorf("Render (empty): equity drawn on an empty series")
+	}
+
+	single, _ := Parse([]byte("timestamp,equity\n2024-01-01,10000\n"), FormatCSV)
+
+	data, _ = Render(single, FormatPNG, 320, 200)
+	img, _  = png.Decode(bytes.NewReader(data))
+	if countColor(img, colorEquity.R, colorEquity.G, colorEquity.B) == 0 {
+		t.Errorf("Render (single point): point not drawn")
+	}
+}
+
+//=============================================================================
+
+func TestDecimate(t *testing.T) {
+	var line []point
+	for i := 0; i < 1000; i++ {
+		line = append(line, point{ x: float64(i) / 100, y: float64(i % 7) })
+	}
+
+	res := decimate(line)
+	if len(res) > 40 || res[0] != line[0] || res[len(res) -1] != line[len(line) -1] {
+		t.Errorf("decimate: got %d points", len(res))
+	}
+}
+
+//=============================================================================
+
+func sameImage(t *testing.T, format string, data []byte, expected []byte) bool {
+	if format != FormatPNG {
+		return bytes.Equal(data, expected)
+	}
+
+	//--- Compared pixel by pixel: the encoder may change between Go releases
+	a, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := png.Decode(bytes.NewReader(expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+
+	for y := a.Bounds().Min.Y; y < a.Bounds().Max.Y; y++ {
+		for x := a.Bounds().Min.X; x < a.Bounds().Max.X; x++ {
+			if a.At(x, y) != b.At(x, y) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+//=============================================================================
+
+func countColor(img image.Image, r, g, b uint8) int {
+	count := 0
+
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			pr, pg, pb, _ := img.At(x, y).RGBA()
+			if uint8(pr >> 8) == r && uint8(pg >> 8) == g && uint8(pb >> 8) == b {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+//=============================================================================
@@ -0,0 +1,475 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//=============================================================================
+//=== Minimal equity chart renderer: the equity line in the upper panel and
+//=== the drawdown area in the lower one. PNG and SVG share the same layout,
+//=== so both outputs look the same. Only the SVG has axis labels. An empty
+//=== series gives the bare grid, a single point is drawn as a dot
+//=============================================================================
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	marginLeft   = 64
+	marginRight  = 12
+	marginTop    = 12
+	marginBottom = 24
+	panelGap     = 12
+	gridLines    = 4
+	dotRadius    = 2
+
+	MinWidth  = marginLeft + marginRight + 10
+	MinHeight = marginTop + marginBottom + panelGap + 20
+)
+
+//=============================================================================
+
+var (
+	colorBackground = color.RGBA{ 0xff, 0xff, 0xff, 0xff }
+	colorGrid       = color.RGBA{ 0xe0, 0xe0, 0xe0, 0xff }
+	colorEquity     = color.RGBA{ 0x1f, 0x77, 0xb4, 0xff }
+	colorDrawdown   = color.RGBA{ 0xd6, 0x27, 0x28, 0xff }
+	colorText       = color.RGBA{ 0x55, 0x55, 0x55, 0xff }
+
+	drawdownAlpha = 0.35
+)
+
+//=============================================================================
+
+type point struct {
+	x, y float64
+}
+
+//=============================================================================
+
+type panel struct {
+	top, bottom float64
+	min, max    float64
+}
+
+//=============================================================================
+
+type layout struct {
+	width, height int
+	left, right   float64
+	equity        panel
+	drawdown      panel
+	equityLine    []point
+	drawdownLine  []point
+	from, to      string
+}
+
+//=============================================================================
+//===
+//=== Public functions
+//===
+//=============================================================================
+
+func Render(points []Point, format string, width int, height int) ([]byte, error) {
+	if width < MinWidth || height < MinHeight {
+		return nil, fmt.Errorf("chart size too small: %dx%d", width, height)
+	}
+
+	l := newLayout(points, width, height)
+
+	switch format {
+	case FormatPNG:
+		return renderPNG(l)
+	case FormatSVG:
+		return renderSVG(l), nil
+	}
+
+	return nil, fmt.Errorf("unknown format: %s", format)
+}
+
+//=============================================================================
+//===
+//=== Layout
+//===
+//=============================================================================
+
+func newLayout(points []Point, width int, height int) *layout {
+	l := &layout{
+		width : width,
+		height: height,
+		left  : marginLeft,
+		right : float64(width - marginRight),
+	}
+
+	plotH := float64(height - marginTop - marginBottom - panelGap)
+
+	l.equity   = panel{ top: marginTop, bottom: marginTop + plotH * 0.7 }
+	l.drawdown = panel{ top: l.equity.bottom + panelGap, bottom: float64(height - marginBottom) }
+
+	if len(points) == 0 {
+		l.equity.min, l.equity.max     = 0, 1
+		l.drawdown.min, l.drawdown.max = -1, 0
+		return l
+	}
+
+	l.from = points[0].Time.UTC().Format(dateLayout)
+	l.to   = points[len(points) -1].Time.UTC().Format(dateLayout)
+
+	l.equity.min, l.equity.max = math.Inf(1), math.Inf(-1)
+	l.drawdown.min, l.drawdown.max = 0, 0
+
+	for _, p := range points {
+		l.equity.min   = math.Min(l.equity.min, p.Equity)
+		l.equity.max   = math.Max(l.equity.max, p.Equity)
+		l.drawdown.min = math.Min(l.drawdown.min, p.Drawdown)
+	}
+
+	pad := (l.equity.max - l.equity.min) * 0.05
+	if pad == 0 {
+		pad = math.Max(1, math.Abs(l.equity.max) * 0.05)
+	}
+	l.equity.min -= pad
+	l.equity.max += pad
+
+	if l.drawdown.min == 0 {
+		l.drawdown.min = -1
+	}
+
+	t0   := points[0].Time
+	span := points[len(points) -1].Time.Sub(t0).Seconds()
+
+	for i, p := range points {
+		x := (l.left + l.right) / 2
+		if span > 0 {
+			x = l.left + (l.right - l.left) * p.Time.Sub(t0).Seconds() / span
+		} else if len(points) > 1 {
+			x = l.left + (l.right - l.left) * float64(i) / float64(len(points) -1)
+		}
+
+		l.equityLine   = append(l.equityLine,   point{ x, l.equity.toY(p.Equity) })
+		l.drawdownLine = append(l.drawdownLine, point{ x, l.drawdown.toY(p.Drawdown) })
+	}
+
+	l.equityLine   = decimate(l.equityLine)
+	l.drawdownLine = decimate(l.drawdownLine)
+
+	return l
+}
+
+//=============================================================================
+
+func (p *panel) toY(value float64) float64 {
+	return p.bottom - (value - p.min) / (p.max - p.min) * (p.bottom - p.top)
+}
+
+//=============================================================================
+
+func (p *panel) gridValue(i int) float64 {
+	return p.min + (p.max - p.min) * float64(i) / gridLines
+}
+
+//=============================================================================
+//=== Keeps, for every pixel column, only the first, last, lowest and highest
+//=== points. The shape does not change but big series become much lighter
+
+func decimate(line []point) []point {
+	if len(line) <= 4 {
+		return line
+	}
+
+	var res []point
+
+	for i := 0; i < len(line); {
+		col := math.Floor(line[i].x)
+		j   := i
+
+		lo, hi := i, i
+		for j < len(line) && math.Floor(line[j].x) == col {
+			if line[j].y < line[lo].y { lo = j }
+			if line[j].y > line[hi].y { hi = j }
+			j++
+		}
+
+		for _, k := range uniqueSorted(i, min(lo, hi), max(lo, hi), j -1) {
+			res = append(res, line[k])
+		}
+
+		i = j
+	}
+
+	return res
+}
+
+//=============================================================================
+
+func uniqueSorted(values ...int) []int {
+	var res []int
+
+	for _, v := range values {
+		if len(res) == 0 || res[len(res) -1] != v {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+//=============================================================================
+//===
+//=== SVG output
+//===
+//=============================================================================
+
+func renderSVG(l *layout) []byte {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`,
+		l.width, l.height, l.width, l.height)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`, hexColor(colorBackground))
+
+	for _, p := range []*panel{ &l.equity, &l.drawdown } {
+		for i := 0; i <= gridLines; i++ {
+			v := p.gridValue(i)
+			y := p.toY(v)
+			fmt.Fprintf(b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s"/>`, num(l.left), num(y), num(l.right), num(y), hexColor(colorGrid))
+			fmt.Fprintf(b, `<text x="%s" y="%s" text-anchor="end" fill="%s">%s</text>`, num(l.left -6), num(y +4), hexColor(colorText), label(v, p))
+		}
+	}
+
+	if len(l.equityLine) > 1 {
+		//--- Drawdown area, closed on the zero line
+		zero := l.drawdown.toY(0)
+		b.WriteString(`<path d="M`)
+		b.WriteString(num(l.drawdownLine[0].x) +","+ num(zero))
+		for _, p := range l.drawdownLine {
+			b.WriteString(" L"+ num(p.x) +","+ num(p.y))
+		}
+		fmt.Fprintf(b, ` L%s,%s Z" fill="%s" fill-opacity="%s"/>`, num(l.drawdownLine[len(l.drawdownLine) -1].x), num(zero),
+			hexColor(colorDrawdown), num(drawdownAlpha))
+
+		b.WriteString(`<polyline fill="none" stroke-width="1.5" stroke-linejoin="round" stroke="`+ hexColor(colorEquity) +`" points="`)
+		for i, p := range l.equityLine {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(num(p.x) +","+ num(p.y))
+		}
+		b.WriteString(`"/>`)
+	}
+
+	if len(l.equityLine) == 1 {
+		e, d := l.equityLine[0], l.drawdownLine[0]
+		fmt.Fprintf(b, `<circle cx="%s" cy="%s" r="%d" fill="%s"/>`, num(e.x), num(e.y), dotRadius, hexColor(colorEquity))
+		fmt.Fprintf(b, `<circle cx="%s" cy="%s" r="%d" fill="%s" fill-opacity="%s"/>`, num(d.x), num(d.y), dotRadius,
+			hexColor(colorDrawdown), num(drawdownAlpha))
+	}
+
+	if len(l.equityLine) > 0 {
+		textY := num(float64(l.height) - 8)
+		fmt.Fprintf(b, `<text x="%s" y="%s" fill="%s">%s</text>`, num(l.left), textY, hexColor(colorText), l.from)
+		fmt.Fprintf(b, `<text x="%s" y="%s" text-anchor="end" fill="%s">%s</text>`, num(l.right), textY, hexColor(colorText), l.to)
+	}
+
+	b.WriteString(`</svg>`)
+	return []byte(b.String())
+}
+
+//=============================================================================
+
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v * 100) / 100, 'f', -1, 64)
+}
+
+//=============================================================================
+
+func label(v float64, p *panel) string {
+	decimals := 0
+	if p.max - p.min < 10 {
+		decimals = 2
+	}
+
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+//=============================================================================
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+//=============================================================================
+//===
+//=== PNG output
+//===
+//=============================================================================
+
+func renderPNG(l *layout) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []uint8{ colorBackground.R, colorBackground.G, colorBackground.B, colorBackground.A })
+	}
+
+	for _, p := range []*panel{ &l.equity, &l.drawdown } {
+		for i := 0; i <= gridLines; i++ {
+			y := math.Round(p.toY(p.gridValue(i)))
+			drawLine(img, l.left, y, l.right, y, colorGrid, 1)
+		}
+	}
+
+	if len(l.equityLine) == 1 {
+		drawDot(img, l.equityLine[0],   colorEquity,   1)
+		drawDot(img, l.drawdownLine[0], colorDrawdown, drawdownAlpha)
+	} else {
+		fillArea(img, l.drawdownLine, l.drawdown.toY(0), colorDrawdown, drawdownAlpha)
+	}
+
+	for i := 1; i < len(l.equityLine); i++ {
+		a, b := l.equityLine[i-1], l.equityLine[i]
+		drawLine(img, a.x, a.y, b.x, b.y, colorEquity, 1)
+	}
+
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, img)
+
+	return buf.Bytes(), err
+}
+
+//=============================================================================
+//=== Fills, column by column, the area between the base line and the line,
+//=== which is interpolated between its points
+
+func fillArea(img *image.RGBA, line []point, base float64, c color.RGBA, alpha float64) {
+	if len(line) == 0 {
+		return
+	}
+
+	for x := int(math.Ceil(line[0].x)); x <= int(math.Floor(line[len(line) -1].x)); x++ {
+		y := interpolate(line, float64(x))
+
+		for py := int(math.Round(base)); py < int(math.Round(y)); py++ {
+			blend(img, x, py, c, alpha)
+		}
+	}
+}
+
+//=============================================================================
+
+func interpolate(line []point, x float64) float64 {
+	for i := 1; i < len(line); i++ {
+		if x <= line[i].x {
+			a, b := line[i-1], line[i]
+			if b.x == a.x {
+				return math.Max(a.y, b.y)
+			}
+			return a.y + (b.y - a.y) * (x - a.x) / (b.x - a.x)
+		}
+	}
+
+	return line[len(line) -1].y
+}
+
+//=============================================================================
+//=== Anti-aliased line (Xiaolin Wu)
+
+func drawLine(img *image.RGBA, x0, y0, x1, y1 float64, c color.RGBA, alpha float64) {
+	steep := math.Abs(y1 - y0) > math.Abs(x1 - x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, x1, y0, y1 = x1, x0, y1, y0
+	}
+
+	plot := func(x, y int, v float64) {
+		if steep {
+			blend(img, y, x, c, v * alpha)
+		} else {
+			blend(img, x, y, c, v * alpha)
+		}
+	}
+
+	gradient := 1.0
+	if dx := x1 - x0; dx != 0 {
+		gradient = (y1 - y0) / dx
+	}
+
+	start := math.Round(x0)
+	y     := y0 + gradient * (start - x0)
+
+	for x := int(start); x <= int(math.Round(x1)); x++ {
+		fy := math.Floor(y)
+		f  := y - fy
+		plot(x, int(fy),    1 - f)
+		plot(x, int(fy) +1, f)
+		y += gradient
+	}
+}
+
+//=============================================================================
+
+func drawDot(img *image.RGBA, p point, c color.RGBA, alpha float64) {
+	cx, cy := int(math.Round(p.x)), int(math.Round(p.y))
+
+	for dy := -dotRadius; dy <= dotRadius; dy++ {
+		for dx := -dotRadius; dx <= dotRadius; dx++ {
+			if dx*dx + dy*dy <= dotRadius*dotRadius {
+				blend(img, cx + dx, cy + dy, c, alpha)
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func blend(img *image.RGBA, x int, y int, c color.RGBA, alpha float64) {
+	if !(image.Point{ x, y }.In(img.Rect)) || alpha <= 0 {
+		return
+	}
+
+	alpha = math.Min(1, alpha)
+	i    := img.PixOffset(x, y)
+
+	mix := func(dst uint8, src uint8) uint8 {
+		return uint8(math.Round(float64(dst) * (1 - alpha) + float64(src) * alpha))
+	}
+
+	img.Pix[i]    = mix(img.Pix[i],    c.R)
+	img.Pix[i +1] = mix(img.Pix[i +1], c.G)
+	img.Pix[i +2] = mix(img.Pix[i +2], c.B)
+	img.Pix[i +3] = 0xff
+}
+
+//=============================================================================
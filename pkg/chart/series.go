@@ -0,0 +1,232 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package chart
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+//=== Equity series: one point per timestamp, with the equity value and the
+//=== drawdown from the previous peak (zero or negative)
+//=============================================================================
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	MaxPoints  = 500_000
+	dateLayout = "2006-01-02"
+)
+
+//=============================================================================
+
+var csvHeader = []string{ "timestamp", "equity", "drawdown" }
+
+//=============================================================================
+
+type Point struct {
+	Time     time.Time `json:"time"`
+	Equity   float64   `json:"equity"`
+	Drawdown float64   `json:"drawdown"`
+}
+
+//=============================================================================
+//===
+//=== Public functions
+//===
+//=============================================================================
+
+func Parse(data []byte, format string) ([]Point, error) {
+	var points []Point
+	var err error
+
+	switch format {
+	case FormatCSV:
+		points, err = parseCSV(data)
+	case FormatJSON:
+		points, err = parseJSON(data)
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return points, validate(points)
+}
+
+//=============================================================================
+
+func ToCSV(points []Point) []byte {
+	buf := &bytes.Buffer{}
+	w   := csv.NewWriter(buf)
+
+	_ = w.Write(csvHeader)
+
+	for _, p := range points {
+		_ = w.Write([]string{
+			p.Time.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(p.Equity,   'f', -1, 64),
+			strconv.FormatFloat(p.Drawdown, 'f', -1, 64),
+		})
+	}
+
+	w.Flush()
+	return buf.Bytes()
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func parseJSON(data []byte) ([]Point, error) {
+	var points []Point
+
+	err := json.Unmarshal(data, &points)
+	if err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+//=============================================================================
+//=== The header is mandatory. The drawdown column is optional and, when
+//=== missing, is computed from the equity
+
+func parseCSV(data []byte) ([]Point, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header: %w", err)
+	}
+
+	for i, h := range header {
+		if i >= len(csvHeader) || !strings.EqualFold(strings.TrimSpace(h), csvHeader[i]) {
+			return nil, errors.New("header must be: timestamp,equity[,drawdown]")
+		}
+	}
+
+	if len(header) < 2 {
+		return nil, errors.New("header must be: timestamp,equity[,drawdown]")
+	}
+
+	var points []Point
+	peak := math.Inf(-1)
+
+	for line := 2; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) != len(header) {
+			return nil, fmt.Errorf("line %d: expected %d fields", line, len(header))
+		}
+
+		p := Point{}
+
+		p.Time, err = parseTime(rec[0])
+		if err == nil {
+			p.Equity, err = strconv.ParseFloat(rec[1], 64)
+		}
+		if err == nil && len(rec) > 2 {
+			p.Drawdown, err = strconv.ParseFloat(rec[2], 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if len(rec) == 2 {
+			peak       = math.Max(peak, p.Equity)
+			p.Drawdown = p.Equity - peak
+		}
+
+		points = append(points, p)
+
+		if len(points) > MaxPoints {
+			return nil, fmt.Errorf("too many points (max %d)", MaxPoints)
+		}
+	}
+
+	return points, nil
+}
+
+//=============================================================================
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(dateLayout, value)
+}
+
+//=============================================================================
+
+func validate(points []Point) error {
+	if len(points) == 0 {
+		return errors.New("the series is empty")
+	}
+
+	if len(points) > MaxPoints {
+		return fmt.Errorf("too many points (max %d)", MaxPoints)
+	}
+
+	for i, p := range points {
+		if math.IsNaN(p.Equity) || math.IsInf(p.Equity, 0) || math.IsNaN(p.Drawdown) || math.IsInf(p.Drawdown, 0) {
+			return fmt.Errorf("point %d: invalid value", i)
+		}
+		if p.Drawdown > 0 {
+			return fmt.Errorf("point %d: drawdown must be zero or negative", i)
+		}
+		if i > 0 && p.Time.Before(points[i-1].Time) {
+			return fmt.Errorf("point %d: timestamps must be in ascending order", i)
+		}
+	}
+
+	return nil
+}
+
+//=============================================================================
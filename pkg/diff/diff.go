@@ -0,0 +1,363 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+//=============================================================================
+//=== Line based diff (Myers' algorithm) with unified output
+//=============================================================================
+
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+
+	DefaultContext = 3
+)
+
+//=============================================================================
+
+type Line struct {
+	Kind    string `json:"kind"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+//=============================================================================
+
+type Hunk struct {
+	OldStart int     `json:"oldStart"`
+	OldLines int     `json:"oldLines"`
+	NewStart int     `json:"newStart"`
+	NewLines int     `json:"newLines"`
+	Lines    []*Line `json:"lines"`
+}
+
+//=============================================================================
+//===
+//=== Public functions
+//===
+//=============================================================================
+
+func Compute(oldText string, newText string, context int) []*Hunk {
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+	return buildHunks(lines, context)
+}
+
+//=============================================================================
+
+func Unified(hunks []*Hunk, oldName string, newName string) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- "+ oldName +"\n")
+	sb.WriteString("+++ "+ newName +"\n")
+
+	for _, h := range hunks {
+		sb.WriteString("@@ -"+ formatRange(h.OldStart, h.OldLines) +" +"+ formatRange(h.NewStart, h.NewLines) +" @@\n")
+
+		for _, l := range h.Lines {
+			switch l.Kind {
+			case Equal:
+				sb.WriteString(" ")
+			case Delete:
+				sb.WriteString("-")
+			case Insert:
+				sb.WriteString("+")
+			}
+
+			sb.WriteString(l.Text +"\n")
+		}
+	}
+
+	return sb.String()
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+//=============================================================================
+//=== Myers' O(ND) algorithm in linear space: the middle of an optimal path
+//=== is found by searching forward and backward at the same time, then both
+//=== halves are diffed recursively. Only two vectors of N+M entries are kept
+//=== per step, so memory does not grow with the edit distance.
+
+type differ struct {
+	a     []string
+	b     []string
+	lines []*Line
+}
+
+//=============================================================================
+
+func diffLines(a []string, b []string) []*Line {
+	d := &differ{
+		a    : a,
+		b    : b,
+		lines: make([]*Line, 0, max(len(a), len(b))),
+	}
+
+	d.compare(0, len(a), 0, len(b))
+	return d.lines
+}
+
+//=============================================================================
+
+func (d *differ) compare(aLo int, aHi int, bLo int, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.equal(aLo, bLo)
+		aLo++
+		bLo++
+	}
+
+	suffix := 0
+	for aLo < aHi - suffix && bLo < bHi - suffix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+
+	aHi -= suffix
+	bHi -= suffix
+
+	if x, y, ok := d.split(aLo, aHi, bLo, bHi); ok {
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	} else {
+		for x := aLo; x < aHi; x++ {
+			d.lines = append(d.lines, &Line{ Kind: Delete, Text: d.a[x], OldLine: x +1 })
+		}
+		for y := bLo; y < bHi; y++ {
+			d.lines = append(d.lines, &Line{ Kind: Insert, Text: d.b[y], NewLine: y +1 })
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.equal(aHi +i, bHi +i)
+	}
+}
+
+//=============================================================================
+//=== Returns a point of an optimal path between the two ranges, or false if
+//=== the ranges have nothing in common. Common prefix and suffix must have
+//=== been removed, so that the point is never one of the corners.
+
+func (d *differ) split(aLo int, aHi int, bLo int, bHi int) (int, int, bool) {
+	n, m := aHi - aLo, bHi - bLo
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	maxD   := (n + m +1) / 2
+	offset := maxD +1
+	delta  := n - m
+	front  := delta % 2 != 0
+
+	//--- Furthest x reached on each diagonal, forward and backward
+	vf := make([]int, 2*maxD +3)
+	vb := make([]int, 2*maxD +3)
+	for i := range vf {
+		vf[i] = -1
+		vb[i] = -1
+	}
+	vf[offset +1] = 0
+	vb[offset +1] = 0
+
+	//--- Diagonals that left the ranges are not followed anymore
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+
+	for step := 0; step < maxD; step++ {
+		for k := -step + fStart; k <= step - fEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] +1
+			}
+
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+
+			vf[offset+k] = x
+
+			if x > n {
+				fEnd += 2
+			} else if y > m {
+				fStart += 2
+			} else if front {
+				if kb := offset + delta - k; kb >= 0 && kb < len(vb) && vb[kb] != -1 && x >= n - vb[kb] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		for k := -step + bStart; k <= step - bEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && vb[offset+k-1] < vb[offset+k+1]) {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] +1
+			}
+
+			y := x - k
+			for x < n && y < m && d.a[aHi-x-1] == d.b[bHi-y-1] {
+				x++
+				y++
+			}
+
+			vb[offset+k] = x
+
+			if x > n {
+				bEnd += 2
+			} else if y > m {
+				bStart += 2
+			} else if !front {
+				if kf := offset + delta - k; kf >= 0 && kf < len(vf) && vf[kf] != -1 {
+					xf := vf[kf]
+					if xf >= n - x {
+						return aLo + xf, bLo + xf - (kf - offset), true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+//=============================================================================
+
+func (d *differ) equal(x int, y int) {
+	d.lines = append(d.lines, &Line{ Kind: Equal, Text: d.a[x], OldLine: x +1, NewLine: y +1 })
+}
+
+//=============================================================================
+//=== Groups changes into hunks, keeping 'context' unchanged lines around them
+//=== and merging hunks that would overlap
+
+func buildHunks(lines []*Line, context int) []*Hunk {
+	hunks := []*Hunk{}
+
+	i := 0
+	for i < len(lines) {
+		if lines[i].Kind == Equal {
+			i++
+			continue
+		}
+
+		start := max(i - context, 0)
+		end   := i
+
+		//--- Extend the hunk while the next change is close enough
+
+		for j := i; j < len(lines); j++ {
+			if lines[j].Kind != Equal {
+				end = j
+			} else if j - end > 2*context {
+				break
+			}
+		}
+
+		end = min(end + context, len(lines) -1)
+
+		hunks = append(hunks, newHunk(lines, start, end))
+		i = end +1
+	}
+
+	return hunks
+}
+
+//=============================================================================
+
+func newHunk(lines []*Line, start int, end int) *Hunk {
+	h := &Hunk{
+		Lines: lines[start : end+1],
+	}
+
+	oldPos, newPos := 1, 1
+
+	for _, l := range lines[:start] {
+		if l.Kind != Insert {
+			oldPos++
+		}
+		if l.Kind != Delete {
+			newPos++
+		}
+	}
+
+	for _, l := range h.Lines {
+		if l.Kind != Insert {
+			h.OldLines++
+		}
+		if l.Kind != Delete {
+			h.NewLines++
+		}
+	}
+
+	//--- Unified format: an empty range refers to the line before it
+
+	h.OldStart = oldPos
+	if h.OldLines == 0 {
+		h.OldStart--
+	}
+
+	h.NewStart = newPos
+	if h.NewLines == 0 {
+		h.NewStart--
+	}
+
+	return h
+}
+
+//=============================================================================
+
+func formatRange(start int, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+
+	return strconv.Itoa(start) +","+ strconv.Itoa(count)
+}
+
+//=============================================================================
@@ -0,0 +1,204 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package diff
+
+import (
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{
+			name    : "identical",
+			oldText : "a\nb\nc\n",
+			newText : "a\nb\nc\n",
+			expected: "",
+		},
+		{
+			name    : "from empty",
+			oldText : "",
+			newText : "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name    : "to empty",
+			oldText : "a\n",
+			newText : "",
+			expected: "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name    : "change in the middle",
+			oldText : "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			newText : "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expected: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name    : "distant changes",
+			oldText : "a\n1\n2\n3\n4\n5\n6\n7\nb\n",
+			newText : "A\n1\n2\n3\n4\n5\n6\n7\nB\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n 3\n@@ -6,4 +6,4 @@\n 5\n 6\n 7\n-b\n+B\n",
+		},
+		{
+			name    : "close changes are merged",
+			oldText : "a\n1\n2\nb\n",
+			newText : "A\n1\n2\nB\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-a\n+A\n 1\n 2\n-b\n+B\n",
+		},
+	}
+
+	for _, test := range tests {
+		hunks := Compute(test.oldText, test.newText, DefaultContext)
+		res   := Unified(hunks, "old", "new")
+
+		if res != test.expected {
+			t.Errorf("%s:\n got: %q\nwant: %q", test.name, res, test.expected)
+		}
+	}
+}
+
+//=============================================================================
+//=== Applying the diff to the old text must give back the new one
+
+func TestCompute_RoundTrip(t *testing.T) {
+	oldText := "the\nquick\nbrown\nfox\njumps\nover\nthe\nlazy\ndog\n"
+	newText := "a\nquick\nred\nfox\njumps\nover\nthe\nvery\nlazy\ncat\n"
+
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	var rebuilt []string
+	for _, l := range lines {
+		if l.Kind != Delete {
+			rebuilt = append(rebuilt, l.Text)
+		}
+	}
+
+	if strings.Join(rebuilt, "\n") +"\n" != newText {
+		t.Errorf("Rebuilt text does not match: %q", rebuilt)
+	}
+}
+
+//=============================================================================
+//=== The script must rebuild both texts with the minimal number of edits
+
+func TestCompute_Minimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		a := randomLines(rnd, rnd.Intn(30))
+		b := randomLines(rnd, rnd.Intn(30))
+
+		var oldLines, newLines []string
+		edits := 0
+
+		for _, l := range diffLines(a, b) {
+			if l.Kind != Insert {
+				oldLines = append(oldLines, l.Text)
+			}
+			if l.Kind != Delete {
+				newLines = append(newLines, l.Text)
+			}
+			if l.Kind != Equal {
+				edits++
+			}
+		}
+
+		if strings.Join(oldLines, "|") != strings.Join(a, "|") || strings.Join(newLines, "|") != strings.Join(b, "|") {
+			t.Fatalf("%v -> %v: bad script", a, b)
+		}
+		if expected := len(a) + len(b) - 2*lcs(a, b); edits != expected {
+			t.Fatalf("%v -> %v: %d edits, expected %d", a, b, edits, expected)
+		}
+	}
+}
+
+//=============================================================================
+//=== Two unrelated documents used to need memory proportional to N*D
+
+func TestCompute_Memory(t *testing.T) {
+	a := make([]string, 3000)
+	b := make([]string, 3000)
+	for i := range a {
+		a[i] = "old line "+ strconv.Itoa(i)
+		b[i] = "new line "+ strconv.Itoa(i)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	hunks := Compute(strings.Join(a, "\n"), strings.Join(b, "\n"), DefaultContext)
+
+	runtime.ReadMemStats(&after)
+
+	if len(hunks) != 1 || len(hunks[0].Lines) != 6000 {
+		t.Fatalf("Compute: got %d hunks", len(hunks))
+	}
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 32 * 1024 * 1024 {
+		t.Errorf("Compute: allocated %d bytes", alloc)
+	}
+}
+
+//=============================================================================
+
+func randomLines(rnd *rand.Rand, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = string(rune('a' + rnd.Intn(4)))
+	}
+
+	return lines
+}
+
+//=============================================================================
+
+func lcs(a []string, b []string) int {
+	prev := make([]int, len(b) +1)
+
+	for i := range a {
+		curr := make([]int, len(b) +1)
+		for j := range b {
+			if a[i] == b[j] {
+				curr[j+1] = prev[j] +1
+			} else {
+				curr[j+1] = max(prev[j+1], curr[j])
+			}
+		}
+		prev = curr
+	}
+
+	return prev[len(b)]
+}
+
+//=============================================================================
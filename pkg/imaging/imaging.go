@@ -0,0 +1,174 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+//=============================================================================
+
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+	FormatGIF  = "gif"
+	FormatWebP = "webp"
+)
+
+//=============================================================================
+
+var ErrUnknownFormat = errors.New("unknown image format")
+
+//=============================================================================
+
+type Info struct {
+	Format      string `json:"format"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+//=============================================================================
+
+var contentTypes = map[string]string{
+	FormatPNG : "image/png",
+	FormatJPEG: "image/jpeg",
+	FormatGIF : "image/gif",
+	FormatWebP: "image/webp",
+}
+
+//=============================================================================
+//===
+//=== Public functions
+//===
+//=============================================================================
+
+func GetContentType(format string) string {
+	return contentTypes[format]
+}
+
+//=============================================================================
+
+func IsWebP(data []byte) bool {
+	return detectFormat(data) == FormatWebP
+}
+
+//=============================================================================
+//=== Identifies the format from the magic bytes, then reads the dimensions
+//=== from the image header
+
+func Detect(data []byte) (*Info, error) {
+	format := detectFormat(data)
+	if format == "" {
+		return nil, ErrUnknownFormat
+	}
+
+	info := &Info{
+		Format     : format,
+		ContentType: contentTypes[format],
+	}
+
+	var err error
+
+	if format == FormatWebP {
+		info.Width, info.Height, err = decodeWebPConfig(data)
+	} else {
+		var cfg image.Config
+		cfg, _, err = image.DecodeConfig(bytes.NewReader(data))
+		info.Width  = cfg.Width
+		info.Height = cfg.Height
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func detectFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return FormatJPEG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return FormatGIF
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return FormatWebP
+	}
+
+	return ""
+}
+
+//=============================================================================
+//=== The standard library has no WebP support: the size is read from the
+//=== first chunk, which can be lossy (VP8), lossless (VP8L) or extended (VP8X)
+
+func decodeWebPConfig(data []byte) (int, int, error) {
+	if len(data) < 30 {
+		return 0, 0, errors.New("webp: truncated header")
+	}
+
+	switch string(data[12:16]) {
+	case "VP8 ":
+		if data[23] != 0x9d || data[24] != 0x01 || data[25] != 0x2a {
+			return 0, 0, errors.New("webp: bad VP8 frame")
+		}
+		w := int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		h := int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+		return w, h, nil
+
+	case "VP8L":
+		if data[20] != 0x2f {
+			return 0, 0, errors.New("webp: bad VP8L signature")
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		w := int(bits & 0x3fff) +1
+		h := int((bits >> 14) & 0x3fff) +1
+		return w, h, nil
+
+	case "VP8X":
+		w := int(uint32(data[24]) | uint32(data[25])<<8 | uint32(data[26])<<16) +1
+		h := int(uint32(data[27]) | uint32(data[28])<<8 | uint32(data[29])<<16) +1
+		return w, h, nil
+	}
+
+	return 0, 0, errors.New("webp: unknown chunk")
+}
+
+//=============================================================================
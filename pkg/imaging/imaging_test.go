@@ -0,0 +1,120 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+//=============================================================================
+
+func encode(t *testing.T, format string, w int, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	buf := &bytes.Buffer{}
+
+	var err error
+	switch format {
+	case FormatPNG:
+		err = png.Encode(buf, img)
+	case FormatJPEG:
+		err = jpeg.Encode(buf, img, nil)
+	case FormatGIF:
+		err = gif.Encode(buf, img, nil)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+//=============================================================================
+
+func webp(chunk string, payload ...byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP"+ chunk +"\x00\x00\x00\x00")
+	return append(data, payload...)
+}
+
+//=============================================================================
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		width  int
+		height int
+	}{
+		{ "png",  encode(t, FormatPNG,  40, 30), FormatPNG,  40, 30 },
+		{ "jpeg", encode(t, FormatJPEG, 16, 8),  FormatJPEG, 16, 8  },
+		{ "gif",  encode(t, FormatGIF,  5, 7),   FormatGIF,  5,  7  },
+
+		//--- 640x480 lossy, 100x50 lossless, 1920x1080 extended
+		{ "webp lossy",    webp("VP8 ", 0, 0, 0, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01), FormatWebP, 640,  480  },
+		{ "webp lossless", webp("VP8L", 0x2f, 0x63, 0x40, 0x0c, 0x00, 0, 0, 0, 0, 0),     FormatWebP, 100,  50   },
+		{ "webp extended", webp("VP8X", 0, 0, 0, 0, 0x7f, 0x07, 0x00, 0x37, 0x04, 0x00),  FormatWebP, 1920, 1080 },
+	}
+
+	for _, test := range tests {
+		info, err := Detect(test.data)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+
+		if info.Format != test.format || info.Width != test.width || info.Height != test.height {
+			t.Errorf("%s: got %+v", test.name, info)
+		}
+		if info.ContentType != GetContentType(test.format) {
+			t.Errorf("%s: bad content type %s", test.name, info.ContentType)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestDetect_Invalid(t *testing.T) {
+	tests := map[string][]byte{
+		"empty"     : nil,
+		"text"      : []byte("hello world"),
+		"svg"       : []byte("<svg xmlns='http://www.w3.org/2000/svg'/>"),
+		"truncated" : []byte("\x89PNG\r\n\x1a\n"),
+		"short webp": []byte("RIFF\x00\x00\x00\x00WEBPVP8 "),
+	}
+
+	for name, data := range tests {
+		if _, err := Detect(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,254 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+)
+
+//=============================================================================
+//=== Image scaling with a box filter: every destination pixel is the area
+//=== weighted average of the source pixels it covers
+//=============================================================================
+
+const (
+	FitContain = "contain"
+	FitCover   = "cover"
+	FitFill    = "fill"
+
+	MaxSize         = 2048
+	MaxSourcePixels = 40_000_000
+	jpegQuality     = 85
+)
+
+//=============================================================================
+
+var ErrNotResizable = errors.New("image format cannot be resized")
+var ErrTooLarge     = errors.New("image is too large to be resized")
+
+//=============================================================================
+
+type weights struct {
+	start  int
+	values []float64
+}
+
+//=============================================================================
+//===
+//=== Public functions
+//===
+//=============================================================================
+//=== Scales the image to fit the width x height box. If one dimension is 0 it
+//=== is derived from the aspect ratio. JPEG stays JPEG, anything else becomes
+//=== PNG. Returns the new image and its format
+
+func Resize(data []byte, width int, height int, fit string) ([]byte, string, error) {
+	info, err := Detect(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if info.Format == FormatWebP {
+		return nil, "", ErrNotResizable
+	}
+
+	if info.Width * info.Height > MaxSourcePixels {
+		return nil, "", ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	dst := scale(toRGBA(src), width, height, fit)
+	buf := &bytes.Buffer{}
+
+	if info.Format == FormatJPEG {
+		err = jpeg.Encode(buf, dst, &jpeg.Options{ Quality: jpegQuality })
+		return buf.Bytes(), FormatJPEG, err
+	}
+
+	err = png.Encode(buf, dst)
+	return buf.Bytes(), FormatPNG, err
+}
+
+//=============================================================================
+//=== Returns the size of the resized image and the source rectangle to use.
+//=== A derived dimension never exceeds MaxSize: for very thin images, the
+//=== given one is scaled down to keep the aspect ratio
+
+func ComputeSize(srcW int, srcH int, width int, height int, fit string) (int, int, image.Rectangle) {
+	full := image.Rect(0, 0, srcW, srcH)
+
+	switch {
+	case width == 0 && height == 0:
+		return srcW, srcH, full
+	case width == 0:
+		width, fit = MaxSize, FitContain
+	case height == 0:
+		height, fit = MaxSize, FitContain
+	}
+
+	switch fit {
+	case FitFill:
+		return width, height, full
+
+	case FitCover:
+		//--- Crops the center of the source to the aspect ratio of the box
+		if srcW * height > width * srcH {
+			cropW := max(1, srcH * width / height)
+			x0    := (srcW - cropW) / 2
+			return width, height, image.Rect(x0, 0, x0 + cropW, srcH)
+		}
+
+		cropH := max(1, srcW * height / width)
+		y0    := (srcH - cropH) / 2
+		return width, height, image.Rect(0, y0, srcW, y0 + cropH)
+	}
+
+	//--- Contain: the largest size that fits the box
+	ratio := math.Min(float64(width) / float64(srcW), float64(height) / float64(srcH))
+	w := max(1, int(math.Round(float64(srcW) * ratio)))
+	h := max(1, int(math.Round(float64(srcH) * ratio)))
+
+	return w, h, full
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+
+	b   := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+
+	return dst
+}
+
+//=============================================================================
+
+func scale(src *image.RGBA, width int, height int, fit string) *image.RGBA {
+	w, h, rect := ComputeSize(src.Rect.Dx(), src.Rect.Dy(), width, height, fit)
+
+	//--- Separable filter: horizontal pass first, then the vertical one
+
+	hw  := computeWeights(rect.Min.X, rect.Dx(), w)
+	vw  := computeWeights(rect.Min.Y, rect.Dy(), h)
+	tmp := make([]float64, w * rect.Dy() * 4)
+
+	for y := 0; y < rect.Dy(); y++ {
+		row := src.Pix[(rect.Min.Y + y) * src.Stride:]
+
+		for x := 0; x < w; x++ {
+			var acc [4]float64
+			for i, wt := range hw[x].values {
+				p := (hw[x].start + i) * 4
+				for ch := 0; ch < 4; ch++ {
+					acc[ch] += float64(row[p + ch]) * wt
+				}
+			}
+			copy(tmp[(y * w + x) * 4:], acc[:])
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var acc [4]float64
+			for i, wt := range vw[y].values {
+				p := ((vw[y].start - rect.Min.Y + i) * w + x) * 4
+				for ch := 0; ch < 4; ch++ {
+					acc[ch] += tmp[p + ch] * wt
+				}
+			}
+
+			d := y * dst.Stride + x * 4
+			for ch := 0; ch < 4; ch++ {
+				dst.Pix[d + ch] = uint8(math.Min(255, math.Max(0, math.Round(acc[ch]))))
+			}
+		}
+	}
+
+	return dst
+}
+
+//=============================================================================
+//=== For each destination pixel, computes the covered source pixels and the
+//=== fraction of each of them. Weights always sum up to 1
+
+func computeWeights(offset int, srcSize int, dstSize int) []weights {
+	list  := make([]weights, dstSize)
+	ratio := float64(srcSize) / float64(dstSize)
+
+	for i := range list {
+		from := float64(i) * ratio
+		to   := from + ratio
+
+		//--- When enlarging, the footprint is smaller than one pixel
+		if ratio < 1 {
+			center := from + ratio/2
+			from = math.Max(0, center - 0.5)
+			to   = math.Min(float64(srcSize), from + 1)
+		}
+
+		first := int(math.Floor(from))
+		last  := min(srcSize, int(math.Ceil(to)))
+
+		w := weights{ start: offset + first }
+		sum := 0.0
+
+		for p := first; p < last; p++ {
+			v := math.Min(to, float64(p +1)) - math.Max(from, float64(p))
+			w.values = append(w.values, v)
+			sum += v
+		}
+
+		for j := range w.values {
+			w.values[j] /= sum
+		}
+
+		list[i] = w
+	}
+
+	return list
+}
+
+//=============================================================================
@@ -0,0 +1,150 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+//=============================================================================
+
+func TestComputeSize(t *testing.T) {
+	tests := []struct {
+		width  int
+		height int
+		fit    string
+		w, h   int
+		rect   image.Rectangle
+	}{
+		{ 0,   0,   FitContain, 400, 200, image.Rect(0, 0, 400, 200)   },
+		{ 100, 0,   FitContain, 100, 50,  image.Rect(0, 0, 400, 200)   },
+		{ 0,   50,  FitCover,   100, 50,  image.Rect(0, 0, 400, 200)   },
+		{ 100, 100, FitContain, 100, 50,  image.Rect(0, 0, 400, 200)   },
+		{ 100, 100, FitFill,    100, 100, image.Rect(0, 0, 400, 200)   },
+		{ 100, 100, FitCover,   100, 100, image.Rect(100, 0, 300, 200) },
+		{ 400, 100, FitCover,   400, 100, image.Rect(0, 50, 400, 150)  },
+	}
+
+	for _, test := range tests {
+		w, h, rect := ComputeSize(400, 200, test.width, test.height, test.fit)
+		if w != test.w || h != test.h || rect != test.rect {
+			t.Errorf("ComputeSize(%d, %d, %s): got %dx%d %v", test.width, test.height, test.fit, w, h, rect)
+		}
+	}
+
+	//--- Extreme aspect ratios: the derived dimension is clamped
+
+	thin := []struct {
+		srcW, srcH    int
+		width, height int
+		w, h          int
+	}{
+		{ 1,     10000, MaxSize, 0,       1,       MaxSize },
+		{ 10000, 1,     0,       MaxSize, MaxSize, 1       },
+		{ 100,   4000,  1000,    0,       51,      MaxSize },
+	}
+
+	for _, test := range thin {
+		w, h, _ := ComputeSize(test.srcW, test.srcH, test.width, test.height, FitCover)
+		if w != test.w || h != test.h {
+			t.Errorf("ComputeSize(%dx%d -> %dx%d): got %dx%d", test.srcW, test.srcH, test.width, test.height, w, h)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestResize(t *testing.T) {
+	//--- Left half black, right half white
+
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{ A: 255 }
+			if x >= 20 {
+				c = color.RGBA{ R: 255, G: 255, B: 255, A: 255 }
+			}
+			src.Set(x, y, c)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	data, format, err := Resize(buf.Bytes(), 1, 1, FitFill)
+	if err != nil || format != FormatPNG {
+		t.Fatalf("Resize: got %s, %v", format, err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _, _, a := img.At(0, 0).RGBA()
+	if img.Bounds().Dx() != 1 || r>>8 < 126 || r>>8 > 129 || a>>8 != 255 {
+		t.Errorf("Resize: expected a grey pixel, got %v", img.At(0, 0))
+	}
+
+	data, _, err = Resize(buf.Bytes(), 10, 0, FitContain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := Detect(data)
+	if err != nil || info.Width != 10 || info.Height != 5 {
+		t.Errorf("Resize: got %+v, %v", info, err)
+	}
+
+	data, format, err = Resize(encode(t, FormatJPEG, 64, 64), 16, 16, FitContain)
+	if err != nil || format != FormatJPEG || detectFormat(data) != FormatJPEG {
+		t.Errorf("Resize (jpeg): got %s, %v", format, err)
+	}
+
+	//--- A thin image asked with only the width stays within MaxSize
+	data, _, err = Resize(encode(t, FormatPNG, 1, 10000), MaxSize, 0, FitContain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err = Detect(data); err != nil || info.Width != 1 || info.Height != MaxSize {
+		t.Errorf("Resize (thin): got %+v, %v", info, err)
+	}
+
+	_, _, err = Resize(webp("VP8L", 0x2f, 0x63, 0x40, 0x0c, 0x00, 0, 0, 0, 0, 0), 10, 10, FitContain)
+	if !errors.Is(err, ErrNotResizable) {
+		t.Errorf("Resize (webp): expected ErrNotResizable, got %v", err)
+	}
+}
+
+//=============================================================================
@@ -26,6 +26,7 @@ package inventory
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/bit-fever/core/msg"
 	"github.com/bit-fever/storage-manager/pkg/backend"
 	"log/slog"
@@ -84,7 +85,7 @@ func addTradingSystem(tsm *TradingSystemMessage) bool {
 		slog.Info("addTradingSystem: Operation complete", "id", tsm.TradingSystem.Id)
 	}
 
-	return err == nil
+	return isProcessed(err)
 }
 
 //=============================================================================
@@ -105,7 +106,7 @@ func updateTradingSystem(tsm *TradingSystemMessage) bool {
 		slog.Info("updateTradingSystem: Operation complete", "id", tsm.TradingSystem.Id)
 	}
 
-	return err == nil
+	return isProcessed(err)
 }
 
 //=============================================================================
@@ -121,6 +122,19 @@ func deleteTradingSystem(tsm *TradingSystemMessage) bool {
 		slog.Info("deleteTradingSystem: Operation complete", "id", tsm.TradingSystem.Id)
 	}
 
+	return isProcessed(err)
+}
+
+//=============================================================================
+//=== Messages that can never be processed are acknowledged and logged,
+//=== otherwise they would be redelivered forever
+
+func isProcessed(err error) bool {
+	if errors.Is(err, backend.ErrInvalidPath) {
+		slog.Error("Dropping message with an invalid username or id!", "error", err.Error())
+		return true
+	}
+
 	return err == nil
 }
 
@@ -0,0 +1,130 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/bit-fever/core/msg"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"io/fs"
+	"testing"
+)
+
+//=============================================================================
+
+func newMessage(t *testing.T, msgType int, ts TradingSystem) *msg.Message {
+	entity, err := json.Marshal(&TradingSystemMessage{ TradingSystem: ts })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &msg.Message{
+		Source: msg.SourceTradingSystem,
+		Type  : msgType,
+		Entity: entity,
+	}
+}
+
+//=============================================================================
+
+func TestHandleMessage_Lifecycle(t *testing.T) {
+	backend.InitMemoryStorage(nil)
+
+	ts := TradingSystem{ Id: 7, Username: "john", Name: "Breakout" }
+
+	if !handleMessage(newMessage(t, msg.TypeCreate, ts)) {
+		t.Fatal("Create: message not acknowledged")
+	}
+
+	info, err := backend.GetTradingSystemInfo("john", 7)
+	if err != nil || info.Name != "Breakout" {
+		t.Fatalf("Create: got %+v, %v", info, err)
+	}
+
+	doc, err := backend.GetTradingSystemDoc("john", 7)
+	if err != nil || doc != "" {
+		t.Fatalf("Create: documentation not initialised: %q, %v", doc, err)
+	}
+
+	ts.Name = "Breakout v2"
+	if !handleMessage(newMessage(t, msg.TypeUpdate, ts)) {
+		t.Fatal("Update: message not acknowledged")
+	}
+
+	info, err = backend.GetTradingSystemInfo("john", 7)
+	if err != nil || info.Name != "Breakout v2" {
+		t.Fatalf("Update: got %+v, %v", info, err)
+	}
+
+	if !handleMessage(newMessage(t, msg.TypeDelete, ts)) {
+		t.Fatal("Delete: message not acknowledged")
+	}
+
+	_, err = backend.GetTradingSystemInfo("john", 7)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("Delete: expected ErrNotExist, got %v", err)
+	}
+}
+
+//=============================================================================
+
+func TestHandleMessage_Dropped(t *testing.T) {
+	backend.InitMemoryStorage(nil)
+
+	tests := []*msg.Message{
+		{ Source: msg.SourceTradingSystem, Type: msg.TypeCreate, Entity: []byte("{bad json") },
+		{ Source: msg.SourcePortfolio,     Type: msg.TypeCreate, Entity: []byte("{}") },
+		{ Source: msg.SourceTradingSystem, Type: msg.TypeNewJob, Entity: []byte("{}") },
+	}
+
+	for _, m := range tests {
+		if !handleMessage(m) {
+			t.Errorf("Message %+v should have been dropped (acknowledged)", m)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestHandleMessage_InvalidPath(t *testing.T) {
+	ms := backend.InitMemoryStorage(nil)
+
+	for _, username := range []string{ "../john", "..", "" } {
+		ts := TradingSystem{ Id: 7, Username: username, Name: "Breakout" }
+
+		for _, msgType := range []int{ msg.TypeCreate, msg.TypeUpdate, msg.TypeDelete } {
+			if !handleMessage(newMessage(t, msgType, ts)) {
+				t.Errorf("Type %d with username %q: message not acknowledged", msgType, username)
+			}
+		}
+	}
+
+	if files, _ := ms.List(""); len(files) != 0 {
+		t.Errorf("Files written outside the storage tree: %v", files)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,90 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package purger
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"log/slog"
+	"time"
+)
+
+//=============================================================================
+
+const (
+	DefaultTrashRetentionDays = 30
+
+	purgeInterval = time.Hour
+)
+
+//=============================================================================
+
+func InitTrashPurger(cfg *app.Config) {
+	retention := GetTrashRetention(&cfg.Storage)
+
+	slog.Info("Starting trash purger...", "retention", retention)
+	go run(retention)
+}
+
+//=============================================================================
+
+func GetTrashRetention(cfg *app.Storage) time.Duration {
+	days := cfg.TrashRetentionDays
+	if days <= 0 {
+		days = DefaultTrashRetentionDays
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func run(retention time.Duration) {
+	for {
+		purge(time.Now(), retention)
+		time.Sleep(purgeInterval)
+	}
+}
+
+//=============================================================================
+
+func purge(now time.Time, retention time.Duration) int {
+	count, err := backend.PurgeTrash(now.Add(-retention))
+
+	if err != nil {
+		slog.Error("purge: Cannot purge the trash", "purged", count, "error", err)
+	} else if count > 0 {
+		slog.Info("purge: Trashed trading systems purged", "purged", count)
+	}
+
+	return count
+}
+
+//=============================================================================
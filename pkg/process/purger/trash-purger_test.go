@@ -0,0 +1,70 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package purger
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+func TestPurge(t *testing.T) {
+	backend.InitMemoryStorage(nil)
+
+	for _, ts := range []*backend.TradingSystem{
+		{ Id: 1, Username: "john", Name: "Breakout"       },
+		{ Id: 2, Username: "jane", Name: "Mean reversion" },
+	} {
+		if err := backend.AddTradingSystem(ts); err != nil {
+			t.Fatal(err)
+		}
+		if err := backend.DeleteTradingSystem(ts.Id, ts.Username); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retention := GetTrashRetention(&app.Storage{})
+	if retention != DefaultTrashRetentionDays * 24 * time.Hour {
+		t.Errorf("GetTrashRetention: bad default %v", retention)
+	}
+
+	if count := purge(time.Now(), retention); count != 0 {
+		t.Errorf("purge: recent entries purged (%d)", count)
+	}
+
+	if count := purge(time.Now().Add(retention + time.Minute), retention); count != 2 {
+		t.Errorf("purge: expected 2 purged entries, got %d", count)
+	}
+
+	if list, err := backend.GetTrashEntries(""); err != nil || len(list) != 0 {
+		t.Errorf("purge: trash not empty, got %v, %v", list, err)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,101 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package scrubber
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"log/slog"
+	"time"
+)
+
+//=============================================================================
+
+const DefaultScrubIntervalHours = 24
+
+//=============================================================================
+
+func InitScrubber(cfg *app.Config) {
+	interval := GetScrubInterval(&cfg.Storage)
+
+	err := backend.LoadLastScrubReport()
+	if err != nil {
+		slog.Error("InitScrubber: Cannot load the last scrub report", "error", err)
+	}
+
+	slog.Info("Starting scrubber...", "interval", interval)
+	go run(interval)
+}
+
+//=============================================================================
+
+func GetScrubInterval(cfg *app.Storage) time.Duration {
+	hours := cfg.ScrubIntervalHours
+	if hours <= 0 {
+		hours = DefaultScrubIntervalHours
+	}
+
+	return time.Duration(hours) * time.Hour
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func run(interval time.Duration) {
+	for {
+		scrub()
+		time.Sleep(interval)
+	}
+}
+
+//=============================================================================
+
+func scrub() *backend.ScrubReport {
+	report, err := backend.Scrub()
+	if report == nil {
+		slog.Error("scrub: Cannot scrub the storage", "error", err)
+		return nil
+	}
+
+	if err != nil {
+		slog.Error("scrub: Cannot save the scrub report", "error", err)
+	}
+
+	for _, i := range report.Issues {
+		slog.Error("scrub: Corrupted file", "owner", i.Username, "id", i.Id, "path", i.Path, "status", i.Status,
+			"expected", i.Expected, "actual", i.Actual, "error", i.Error)
+	}
+
+	slog.Info("scrub: Storage scrubbed", "tradingSystems", report.TradingSystems, "files", report.Files,
+		"adopted", report.Adopted, "issues", len(report.Issues), "duration", report.FinishedAt.Sub(report.StartedAt))
+
+	return report
+}
+
+//=============================================================================
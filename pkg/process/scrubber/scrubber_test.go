@@ -0,0 +1,61 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package scrubber
+
+import (
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+func TestScrub(t *testing.T) {
+	ms := backend.InitMemoryStorage(nil)
+
+	if err := backend.AddTradingSystem(&backend.TradingSystem{ Id: 1, Username: "john", Name: "Breakout" }); err != nil {
+		t.Fatal(err)
+	}
+
+	interval := GetScrubInterval(&app.Storage{})
+	if interval != DefaultScrubIntervalHours * time.Hour {
+		t.Errorf("GetScrubInterval: bad default %v", interval)
+	}
+
+	if report := scrub(); report == nil || report.Files != 2 || len(report.Issues) != 0 {
+		t.Fatalf("scrub: got %+v", report)
+	}
+
+	_ = ms.Put("john/1/"+ backend.InfoFile, []byte("{}"))
+
+	report := scrub()
+	if report == nil || len(report.Issues) != 1 || report.Issues[0].Status != backend.ScrubMismatch {
+		t.Errorf("scrub: expected a mismatch, got %+v", report)
+	}
+}
+
+//=============================================================================
@@ -0,0 +1,99 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/backup"
+	"github.com/bit-fever/storage-manager/pkg/business"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+)
+
+//=============================================================================
+//=== The archive is built in a temporary file first, so that an error can
+//=== still be returned as such and not as a truncated download
+
+func getBackup(c *auth.Context) {
+	username := c.Gin.Param("username")
+	format   := c.GetParamAsString("format", backup.FormatTarGz)
+
+	file, err := os.CreateTemp("", "backup-*")
+	if err != nil {
+		c.ReturnError(req.NewServerErrorByError(err))
+		return
+	}
+
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = business.BackupUser(c, username, format, file)
+
+	var size int64
+	if err == nil {
+		size, err = file.Seek(0, 1)
+	}
+	if err == nil {
+		_, err = file.Seek(0, 0)
+	}
+
+	if err != nil {
+		c.ReturnError(err)
+		return
+	}
+
+	name := username +"-"+ time.Now().UTC().Format("20060102-150405") +"."+ format
+
+	c.Gin.DataFromReader(http.StatusOK, size, backup.GetContentType(format), file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{ "filename": name }),
+	})
+}
+
+//=============================================================================
+
+func restoreBackup(c *auth.Context) {
+	username := c.Gin.Param("username")
+	format   := c.GetParamAsString("format", backup.FormatTarGz)
+	remap    := c.GetParamAsString("remap",  "")
+
+	data, err := readBody(c, business.GetMaxBackupSize(c))
+
+	if err == nil {
+		var res *backup.Result
+		res, err = business.RestoreUser(c, username, format, data, remap)
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
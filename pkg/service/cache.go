@@ -0,0 +1,105 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/business"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+//=== Conditional requests: every stored file is sent with a strong ETag, its
+//=== modification time (when known) and the configured Cache-Control policy
+//=============================================================================
+//=== Sets the cache headers and, if the client copy is still valid, replies
+//=== with 304. Returns true if the response has been sent
+
+func checkNotModified(c *auth.Context, etag string, modTime time.Time) bool {
+	c.Gin.Header("ETag", etag)
+	c.Gin.Header("Cache-Control", business.GetCacheControl(c))
+
+	if !modTime.IsZero() {
+		c.Gin.Header("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if isNotModified(c.Gin.Request, etag, modTime) {
+		c.Gin.Status(http.StatusNotModified)
+		return true
+	}
+
+	return false
+}
+
+//=============================================================================
+
+func buildContentETag(data []byte) string {
+	return `"`+ business.ComputeHash(data) +`"`
+}
+
+//=============================================================================
+//=== If-None-Match takes precedence over If-Modified-Since (RFC 9110, 13.1.3)
+
+func isNotModified(r *http.Request, etag string, modTime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return matchesETag(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || modTime.IsZero() {
+		return false
+	}
+
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	return !modTime.Truncate(time.Second).After(t)
+}
+
+//=============================================================================
+//=== If-None-Match uses the weak comparison
+
+func matchesETag(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+//=============================================================================
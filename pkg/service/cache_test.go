@@ -0,0 +1,75 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//=============================================================================
+
+func TestIsNotModified(t *testing.T) {
+	modTime := time.Date(2026, 3, 1, 10, 30, 15, 500, time.UTC)
+	before  := modTime.Add(-time.Hour).Format(http.TimeFormat)
+	same    := modTime.Format(http.TimeFormat)
+
+	tests := []struct {
+		method string
+		inm    string
+		ims    string
+		result bool
+	}{
+		{ http.MethodGet,  `"abc"`,          "",     true  },
+		{ http.MethodGet,  `W/"abc"`,        "",     true  },
+		{ http.MethodGet,  `"x", "abc"`,     "",     true  },
+		{ http.MethodGet,  `*`,              "",     true  },
+		{ http.MethodGet,  `"xyz"`,          same,   false },
+		{ http.MethodGet,  "",               same,   true  },
+		{ http.MethodGet,  "",               before, false },
+		{ http.MethodGet,  "",               "junk", false },
+		{ http.MethodGet,  "",               "",     false },
+		{ http.MethodPut,  `"abc"`,          "",     false },
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/", nil)
+		if test.inm != "" {
+			r.Header.Set("If-None-Match", test.inm)
+		}
+		if test.ims != "" {
+			r.Header.Set("If-Modified-Since", test.ims)
+		}
+
+		if res := isNotModified(r, `"abc"`, modTime); res != test.result {
+			t.Errorf("isNotModified(%s, %q, %q): expected %v", test.method, test.inm, test.ims, test.result)
+		}
+	}
+}
+
+//=============================================================================
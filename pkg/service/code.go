@@ -0,0 +1,236 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/business"
+)
+
+//=============================================================================
+
+func getCodeFiles(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var res []*business.CodeFile
+		res, err = business.GetCodeFiles(c, tsId)
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getCodeFile(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		name := c.Gin.Param("name")
+
+		var info *business.CodeFile
+		var data []byte
+		info, data, err = business.GetCodeFile(c, tsId, name)
+		if err == nil {
+			returnFile(c, info.Name, info.ContentType, info.ModTime, data)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func uploadCodeFile(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var data []byte
+		data, err = readBody(c, business.GetMaxCodeSize(c))
+
+		if err == nil {
+			var res *business.CodeFile
+			res, err = business.UploadCodeFile(c, tsId, c.Gin.Param("name"), data)
+			if err == nil {
+				_ = c.ReturnObject(res)
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteCodeFile(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = business.DeleteCodeFile(c, tsId, c.Gin.Param("name"))
+		if err == nil {
+			_ = c.ReturnObject("")
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getCodeRevisions(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var res *business.CodeRevisionsResponse
+		res, err = business.GetCodeRevisions(c, tsId, c.Gin.Param("name"))
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getCodeRevision(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var rev int
+		rev, err = getRevisionFromUrl(c)
+
+		if err == nil {
+			var info *business.CodeFile
+			var data []byte
+			info, data, err = business.GetCodeRevision(c, tsId, c.Gin.Param("name"), rev)
+			if err == nil {
+				returnFile(c, info.Name, info.ContentType, info.ModTime, data)
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func tagCodeRevision(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var rev int
+		rev, err = getRevisionFromUrl(c)
+
+		if err == nil {
+			tagReq := business.TagRequest{}
+			err = c.BindParamsFromBody(&tagReq)
+
+			if err == nil {
+				var res *backend.Revision
+				res, err = business.TagCodeRevision(c, tsId, c.Gin.Param("name"), rev, &tagReq)
+				if err == nil {
+					_ = c.ReturnObject(res)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func restoreCodeRevision(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var rev int
+		rev, err = getRevisionFromUrl(c)
+
+		if err == nil {
+			var res *backend.Revision
+			res, err = business.RestoreCodeRevision(c, tsId, c.Gin.Param("name"), rev)
+			if err == nil {
+				_ = c.ReturnObject(res)
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getCodeDeployment(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var res *backend.Deployment
+		res, err = business.GetCodeDeployment(c, tsId, c.Gin.Param("name"))
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func setCodeDeployment(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		depReq := business.DeploymentRequest{}
+		err = c.BindParamsFromBody(&depReq)
+
+		if err == nil {
+			var res *backend.Deployment
+			res, err = business.SetCodeDeployment(c, tsId, c.Gin.Param("name"), &depReq)
+			if err == nil {
+				_ = c.ReturnObject(res)
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -0,0 +1,193 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"errors"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/bit-fever/storage-manager/pkg/business"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//=============================================================================
+
+const multipartOverhead = 1024 * 1024
+
+//=============================================================================
+//=== Reads the raw request body. At most maxSize+1 bytes are read, so that
+//=== business functions can detect and reject oversized files.
+
+func readBody(c *auth.Context, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(c.Gin.Request.Body, maxSize +1))
+	if err != nil {
+		return nil, req.NewBadRequestError("Cannot read request body: %v", err.Error())
+	}
+
+	return data, nil
+}
+
+//=============================================================================
+//=== Parses a multipart/form-data request and returns the content of one of
+//=== its files. As for readBody, at most maxSize+1 bytes are returned.
+
+func readFormFile(c *auth.Context, field string, maxSize int64) ([]byte, error) {
+	c.Gin.Request.Body = http.MaxBytesReader(c.Gin.Writer, c.Gin.Request.Body, maxSize + multipartOverhead)
+
+	err := c.Gin.Request.ParseMultipartForm(maxSize)
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return nil, req.AppError{
+				Code   : http.StatusRequestEntityTooLarge,
+				Message: "Request exceeds the maximum allowed size",
+			}
+		}
+
+		return nil, req.NewBadRequestError("Cannot parse multipart request: %v", err.Error())
+	}
+
+	fh, err := c.Gin.FormFile(field)
+	if err != nil {
+		return nil, req.NewBadRequestError("Missing file in field: %v", field)
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		return nil, req.NewServerErrorByError(err)
+	}
+
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, maxSize +1))
+}
+
+//=============================================================================
+//=== Files are always downloaded as attachments, to avoid that stored HTML
+//=== or SVG content is rendered by the browser in the application's origin
+
+func returnFile(c *auth.Context, name string, contentType string, modTime time.Time, data []byte) {
+	returnContent(c, "attachment", name, contentType, modTime, data)
+}
+
+//=============================================================================
+//=== Validated raster images are safe to be displayed inline
+
+func returnImage(c *auth.Context, name string, contentType string, modTime time.Time, data []byte) {
+	returnContent(c, "inline", name, contentType, modTime, data)
+}
+
+//=============================================================================
+
+func returnContent(c *auth.Context, disposition string, name string, contentType string, modTime time.Time, data []byte) {
+	if checkNotModified(c, buildContentETag(data), modTime) {
+		return
+	}
+
+	c.Gin.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{ "filename": name }))
+	c.Gin.Header("X-Content-Type-Options", "nosniff")
+	_ = c.ReturnData(contentType, data)
+}
+
+//=============================================================================
+//=== SVG charts can contain scripts: the policy prevents their execution when
+//=== the chart is opened directly in the browser
+
+func returnChart(c *auth.Context, ec *business.EquityChart) {
+	c.Gin.Header("X-Content-Type-Options", "nosniff")
+
+	if ec.Format == backend.ChartFormatSVG {
+		c.Gin.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	}
+
+	_ = c.ReturnData(ec.ContentType, ec.Data)
+}
+
+//=============================================================================
+//=== Reads the optional width, height and fit query parameters. Returns nil
+//=== if none of them is present
+
+func getResizeRequest(c *auth.Context) (*business.ResizeRequest, error) {
+	width  := c.Gin.Query("width")
+	height := c.Gin.Query("height")
+	fit    := c.Gin.Query("fit")
+
+	if width == "" && height == "" && fit == "" {
+		return nil, nil
+	}
+
+	rr := &business.ResizeRequest{ Fit: fit }
+
+	var err error
+	if width != "" {
+		rr.Width, err = strconv.Atoi(width)
+	}
+	if err == nil && height != "" {
+		rr.Height, err = strconv.Atoi(height)
+	}
+
+	if err != nil {
+		return nil, req.AppError{
+			Code   : http.StatusBadRequest,
+			Message: "Width and height must be integers",
+		}
+	}
+
+	return rr, nil
+}
+
+//=============================================================================
+//=== Like ReturnError, but the body also carries the partial result of the
+//=== operation (e.g. per-item statuses)
+
+type errorWithResult struct {
+	Code   int    `json:"code"`
+	Error  string `json:"error"`
+	Result any    `json:"result"`
+}
+
+//=============================================================================
+
+func returnErrorWithResult(c *auth.Context, err error, result any) {
+	ae := req.AppError{}
+	if !errors.As(err, &ae) {
+		ae = req.AppError{ Code: http.StatusInternalServerError, Message: err.Error() }
+	}
+
+	c.Log.Error("returnErrorWithResult: Request failed", "code", ae.Code, "error", ae.Message)
+
+	c.Gin.JSON(ae.Code, &errorWithResult{
+		Code  : ae.Code,
+		Error : ae.Message,
+		Result: result,
+	})
+}
+
+//=============================================================================
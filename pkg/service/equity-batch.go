@@ -0,0 +1,249 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/business"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+
+const (
+	ArchiveZip       = "zip"
+	ArchiveMultipart = "multipart"
+
+	BatchManifest = "manifest.json"
+)
+
+//=============================================================================
+//=== Returns the charts of several trading systems as a zip file or as a
+//=== multipart/mixed stream. Both contain a manifest with the outcome for
+//=== every trading system
+
+func getEquityChartBatch(c *auth.Context) {
+	ids, err := getIdsFromQuery(c)
+
+	if err == nil {
+		chartType := c.GetParamAsString("type", "")
+		fallback  := c.Gin.Query("fallback") == FallbackDefault
+
+		var formats []string
+		formats, err = getChartFormatsFromQuery(c)
+
+		var archive string
+		if err == nil {
+			archive, err = getArchiveType(c)
+		}
+
+		if err == nil {
+			var list []*business.BatchChartResult
+			list, err = business.GetEquityChartBatch(c, ids, chartType, formats, fallback)
+			if err == nil {
+				if archive == ArchiveMultipart {
+					err = writeMultipartBatch(c, list)
+				} else {
+					err = writeZipBatch(c, chartType, list)
+				}
+
+				if err != nil {
+					c.Log.Error("getEquityChartBatch: Cannot send the response", "error", err)
+				}
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getIdsFromQuery(c *auth.Context) ([]uint, error) {
+	var ids []uint
+	seen := map[uint]bool{}
+
+	for _, value := range strings.Split(c.Gin.Query("ids"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			return nil, req.AppError{
+				Code   : http.StatusBadRequest,
+				Message: "Invalid trading system id: "+ value,
+			}
+		}
+
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+
+	return ids, nil
+}
+
+//=============================================================================
+//=== The Accept header selects the archive, so the chart format can only be
+//=== forced with ?format=
+
+func getChartFormatsFromQuery(c *auth.Context) ([]string, error) {
+	if c.Gin.Query("format") == "" {
+		return nil, nil
+	}
+
+	return getChartFormats(c)
+}
+
+//=============================================================================
+
+func getArchiveType(c *auth.Context) (string, error) {
+	switch archive := c.Gin.Query("archive"); archive {
+	case ArchiveZip, ArchiveMultipart:
+		return archive, nil
+	case "":
+	default:
+		return "", req.AppError{
+			Code   : http.StatusBadRequest,
+			Message: "Invalid archive (allowed are zip, multipart): "+ archive,
+		}
+	}
+
+	if strings.Contains(c.Gin.GetHeader("Accept"), "multipart/mixed") {
+		return ArchiveMultipart, nil
+	}
+
+	return ArchiveZip, nil
+}
+
+//=============================================================================
+
+func writeZipBatch(c *auth.Context, chartType string, list []*business.BatchChartResult) error {
+	name := chartType +"-equity-charts.zip"
+
+	c.Gin.Header("Content-Type", "application/zip")
+	c.Gin.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{ "filename": name }))
+	c.Gin.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Gin.Writer)
+
+	for _, res := range list {
+		if res.Chart == nil {
+			continue
+		}
+
+		modTime := res.Chart.ModTime
+		if modTime.IsZero() {
+			modTime = time.Now()
+		}
+
+		//--- Images are already compressed
+		w, err := zw.CreateHeader(&zip.FileHeader{ Name: res.Name, Method: zip.Store, Modified: modTime })
+		if err != nil {
+			return err
+		}
+
+		if _, err = w.Write(res.Chart.Data); err != nil {
+			return err
+		}
+	}
+
+	w, err := zw.Create(BatchManifest)
+	if err == nil {
+		err = json.NewEncoder(w).Encode(list)
+	}
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+//=============================================================================
+
+func writeMultipartBatch(c *auth.Context, list []*business.BatchChartResult) error {
+	mw := multipart.NewWriter(c.Gin.Writer)
+
+	c.Gin.Header("Content-Type", "multipart/mixed; boundary="+ mw.Boundary())
+	c.Gin.Status(http.StatusOK)
+
+	for _, res := range list {
+		if res.Chart == nil {
+			continue
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type",        res.Chart.ContentType)
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{ "filename": res.Name }))
+		header.Set("ETag",                `"`+ res.Hash +`"`)
+		header.Set("X-Trading-System-Id", strconv.Itoa(int(res.Id)))
+
+		if res.Placeholder {
+			header.Set(HeaderPlaceholder, "true")
+		}
+
+		w, err := mw.CreatePart(header)
+		if err == nil {
+			_, err = w.Write(res.Chart.Data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type",        "application/json")
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{ "filename": BatchManifest }))
+
+	w, err := mw.CreatePart(header)
+	if err == nil {
+		err = json.NewEncoder(w).Encode(list)
+	}
+	if err != nil {
+		return err
+	}
+
+	return mw.Close()
+}
+
+//=============================================================================
@@ -0,0 +1,149 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+
+package service
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/business"
+	"github.com/bit-fever/storage-manager/pkg/chart"
+	"net/http"
+	"strconv"
+)
+
+//=============================================================================
+
+func getEquityData(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		chartType := c.GetParamAsString("type", "")
+		format    := c.GetParamAsString("format", chart.FormatJSON)
+
+		var edf *business.EquityDataFile
+		var data []byte
+		edf, data, err = business.GetEquityData(c, tsId, chartType, format)
+		if err == nil {
+			returnFile(c, edf.Name, edf.ContentType, edf.ModTime, data)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func renderEquityChart(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		chartType := c.GetParamAsString("type", "")
+		format    := c.GetParamAsString("format", chart.FormatPNG)
+
+		var width, height int
+		width, height, err = getChartSize(c)
+
+		if err == nil {
+			var ec *business.EquityChart
+			ec, err = business.RenderEquityChart(c, tsId, chartType, format, width, height)
+			if err == nil {
+				if !checkNotModified(c, `"`+ ec.Hash +`"`, ec.ModTime) {
+					returnChart(c, ec)
+				}
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func setEquityData(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		edReq := business.NewEquityDataRequest()
+		err = c.BindParamsFromBody(edReq)
+
+		if err == nil {
+			err = business.SetEquityData(c, tsId, edReq)
+			if err == nil {
+				_ = c.ReturnObject("")
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteEquityData(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		edReq := business.NewEquityDataRequest()
+		err = c.BindParamsFromBody(edReq)
+
+		if err == nil {
+			err = business.DeleteEquityData(c, tsId, edReq)
+			if err == nil {
+				_ = c.ReturnObject("")
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getChartSize(c *auth.Context) (int, int, error) {
+	width, err := strconv.Atoi(c.GetParamAsString("width", "0"))
+	if err == nil {
+		var height int
+		height, err = strconv.Atoi(c.GetParamAsString("height", "0"))
+		if err == nil {
+			return width, height, nil
+		}
+	}
+
+	return 0, 0, req.AppError{
+		Code   : http.StatusBadRequest,
+		Message: "Width and height must be integers",
+	}
+}
+
+//=============================================================================
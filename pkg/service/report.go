@@ -0,0 +1,112 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/storage-manager/pkg/business"
+)
+
+//=============================================================================
+
+func getReports(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var res []*business.ReportInfo
+		res, err = business.GetReports(c, tsId)
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getReport(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var info *business.ReportInfo
+		var data []byte
+		info, data, err = business.GetReport(c, tsId, c.Gin.Param("name"))
+		if err == nil {
+			returnFile(c, info.Name, info.ContentType, info.ModTime, data)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+//=== Expects a multipart/form-data request with the report in the 'file'
+//=== field and the metadata in the other ones
+
+func uploadReport(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var data []byte
+		data, err = readFormFile(c, "file", business.GetMaxReportSize(c))
+
+		if err == nil {
+			repReq := business.ReportRequest{}
+			err = c.BindParamsFromBody(&repReq)
+
+			if err == nil {
+				var res *business.ReportInfo
+				res, err = business.UploadReport(c, tsId, c.Gin.Param("name"), &repReq, data)
+				if err == nil {
+					_ = c.ReturnObject(res)
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func deleteReport(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		err = business.DeleteReport(c, tsId, c.Gin.Param("name"))
+		if err == nil {
+			_ = c.ReturnObject("")
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
@@ -26,6 +26,7 @@ package service
 
 import (
 	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/auth/role"
 	"github.com/bit-fever/core/auth/roles"
 	"github.com/bit-fever/core/req"
 	"github.com/bit-fever/storage-manager/pkg/app"
@@ -35,16 +36,70 @@ import (
 
 //=============================================================================
 
+type secureFunc func(h auth.RestService, roles []role.Role) func(c *gin.Context)
+
+//=============================================================================
+
 func Init(router *gin.Engine, cfg *app.Config, logger *slog.Logger) {
 
 	ctrl := auth.NewOidcController(cfg.Authentication.Authority, req.GetClient("bf"), logger, cfg)
 
-	router.GET("/api/storage/v1/trading-systems/:id/documentation",  ctrl.Secure(getDocumentation, roles.Admin_User))
-	router.PUT("/api/storage/v1/trading-systems/:id/documentation",  ctrl.Secure(setDocumentation, roles.Admin_User))
+	registerRoutes(router, ctrl.Secure)
+}
+
+//=============================================================================
+
+func registerRoutes(router *gin.Engine, secure secureFunc) {
+	router.GET("/api/storage/v1/trading-systems/:id/documentation",  secure(getDocumentation, roles.Admin_User))
+	router.PUT("/api/storage/v1/trading-systems/:id/documentation",  secure(setDocumentation, roles.Admin_User))
+
+	router.GET ("/api/storage/v1/trading-systems/:id/documentation/versions",              secure(getDocumentationVersions,    roles.Admin_User))
+	router.GET ("/api/storage/v1/trading-systems/:id/documentation/versions/:rev",         secure(getDocumentationVersion,     roles.Admin_User))
+	router.POST("/api/storage/v1/trading-systems/:id/documentation/versions/:rev/restore", secure(restoreDocumentationVersion, roles.Admin_User))
+	router.GET ("/api/storage/v1/trading-systems/:id/documentation/diff",                  secure(getDocumentationDiff,        roles.Admin_User))
+
+	router.GET   ("/api/storage/v1/trading-systems/:id/code",       secure(getCodeFiles,   roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/code/:name", secure(getCodeFile,    roles.Admin_User))
+	router.PUT   ("/api/storage/v1/trading-systems/:id/code/:name", secure(uploadCodeFile, roles.Admin_User))
+	router.DELETE("/api/storage/v1/trading-systems/:id/code/:name", secure(deleteCodeFile, roles.Admin_User))
+
+	router.GET ("/api/storage/v1/trading-systems/:id/code/:name/revisions",              secure(getCodeRevisions,    roles.Admin_User))
+	router.GET ("/api/storage/v1/trading-systems/:id/code/:name/revisions/:rev",         secure(getCodeRevision,     roles.Admin_User))
+	router.POST("/api/storage/v1/trading-systems/:id/code/:name/revisions/:rev/tags",    secure(tagCodeRevision,     roles.Admin_User))
+	router.POST("/api/storage/v1/trading-systems/:id/code/:name/revisions/:rev/restore", secure(restoreCodeRevision, roles.Admin_User))
+	router.GET ("/api/storage/v1/trading-systems/:id/code/:name/deployment",             secure(getCodeDeployment,   roles.Admin_User))
+	router.PUT ("/api/storage/v1/trading-systems/:id/code/:name/deployment",             secure(setCodeDeployment,   roles.Admin_User))
+
+	router.GET   ("/api/storage/v1/trading-systems/:id/reports",       secure(getReports,   roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/reports/:name", secure(getReport,    roles.Admin_User))
+	router.PUT   ("/api/storage/v1/trading-systems/:id/reports/:name", secure(uploadReport, roles.Admin_User))
+	router.DELETE("/api/storage/v1/trading-systems/:id/reports/:name", secure(deleteReport, roles.Admin_User))
+
+	router.GET   ("/api/storage/v1/trading-systems/:id/images",       secure(getImages,   roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/images/:name", secure(getImage,    roles.Admin_User))
+	router.PUT   ("/api/storage/v1/trading-systems/:id/images/:name", secure(uploadImage, roles.Admin_User))
+	router.DELETE("/api/storage/v1/trading-systems/:id/images/:name", secure(deleteImage, roles.Admin_User))
+
+	router.GET("/api/storage/v1/equity-charts", secure(getEquityChartBatch, roles.Admin_User))
+
+	router.GET ("/api/storage/v1/admin/trash",                          secure(getTrash,       roles.Admin))
+	router.POST("/api/storage/v1/admin/trash/:username/:entry/restore", secure(restoreTrash,   roles.Admin))
+	router.GET ("/api/storage/v1/admin/backups/:username",              secure(getBackup,      roles.Admin))
+	router.POST("/api/storage/v1/admin/backups/:username/restore",      secure(restoreBackup,  roles.Admin))
+	router.GET ("/api/storage/v1/admin/scrub",                          secure(getScrubReport, roles.Admin))
+
+	router.GET   ("/api/storage/v1/trading-systems/:id/equity-charts",                   secure(getEquityCharts,         roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/equity-chart",                    secure(getEquityChart,          roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/equity-chart/metadata",           secure(getEquityChartMetadata,  roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/equity-chart/history",            secure(getEquityChartSnapshots, roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/equity-chart/history/:timestamp", secure(getEquityChartSnapshot,  roles.Admin_User))
+	router.PUT   ("/api/storage/v1/trading-systems/:id/equity-chart",                    secure(setEquityCharts,         roles.Service))
+	router.DELETE("/api/storage/v1/trading-systems/:id/equity-chart",                    secure(deleteEquityCharts,      roles.Service))
 
-	router.GET   ("/api/storage/v1/trading-systems/:id/equity-chart",   ctrl.Secure(getEquityChart,     roles.Admin_User))
-	router.PUT   ("/api/storage/v1/trading-systems/:id/equity-chart",   ctrl.Secure(setEquityCharts,    roles.Service))
-	router.DELETE("/api/storage/v1/trading-systems/:id/equity-chart",   ctrl.Secure(deleteEquityCharts, roles.Service))
+	router.GET   ("/api/storage/v1/trading-systems/:id/equity-data",       secure(getEquityData,     roles.Admin_User))
+	router.GET   ("/api/storage/v1/trading-systems/:id/equity-data/chart", secure(renderEquityChart, roles.Admin_User))
+	router.PUT   ("/api/storage/v1/trading-systems/:id/equity-data",       secure(setEquityData,     roles.Service))
+	router.DELETE("/api/storage/v1/trading-systems/:id/equity-data",       secure(deleteEquityData,  roles.Service))
 }
 
 //=============================================================================
@@ -0,0 +1,1184 @@
+//=============================================================================
+/*
+Copyright © 2025 Andrea Carboni andrea.carboni71@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+//=============================================================================
+
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/auth/role"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/app"
+	"github.com/bit-fever/storage-manager/pkg/backend"
+	"github.com/gin-gonic/gin"
+	"image"
+	"image/png"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+//=============================================================================
+//=== Test infrastructure: the OIDC controller is replaced by a fake one that
+//=== takes username and role from the X-Test-User / X-Test-Role headers
+//=============================================================================
+
+const (
+	headerUser = "X-Test-User"
+	headerRole = "X-Test-Role"
+)
+
+var defaultChart = []byte("default-chart")
+
+//=============================================================================
+
+func fakeSecure(cfg *app.Config) secureFunc {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return func(h auth.RestService, roles []role.Role) func(c *gin.Context) {
+		return func(c *gin.Context) {
+			us := &auth.UserSession{
+				Username: c.GetHeader(headerUser),
+				Roles   : map[role.Role]any{ role.Role(c.GetHeader(headerRole)): nil },
+			}
+
+			if ! us.IsUserInRole(roles) {
+				req.ReturnForbiddenError(c, "User not allowed to access this API: "+ us.Username)
+				return
+			}
+
+			h(&auth.Context{
+				Gin    : c,
+				Session: us,
+				Log    : logger,
+				Config : cfg,
+			})
+		}
+	}
+}
+
+//=============================================================================
+
+func newTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	backend.InitMemoryStorage(defaultChart)
+
+	err := backend.AddTradingSystem(&backend.TradingSystem{ Id: 1, Username: "john", Name: "Breakout" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	registerRoutes(router, fakeSecure(&app.Config{}))
+
+	return router
+}
+
+//=============================================================================
+
+func call(router *gin.Engine, method string, url string, user string, r role.Role, body any, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader = http.NoBody
+
+	if raw, ok := body.([]byte); ok {
+		reader = bytes.NewReader(raw)
+	} else if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+
+	request := httptest.NewRequest(method, url, reader)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(headerUser, user)
+	request.Header.Set(headerRole, string(r))
+
+	for i := 0; i < len(headers) -1; i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+//=============================================================================
+//=== Tests
+//=============================================================================
+
+const (
+	urlDoc   = "/api/storage/v1/trading-systems/1/documentation"
+	urlChart = "/api/storage/v1/trading-systems/1/equity-chart"
+)
+
+//=============================================================================
+
+func TestRoutes_Authorization(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		method string
+		url    string
+		role   role.Role
+		status int
+	}{
+		{ http.MethodGet,    urlDoc,   role.Service, http.StatusForbidden },
+		{ http.MethodPut,    urlDoc,   role.Service, http.StatusForbidden },
+		{ http.MethodGet,    urlChart, role.Service, http.StatusForbidden },
+		{ http.MethodPut,    urlChart, role.User,    http.StatusForbidden },
+		{ http.MethodDelete, urlChart, role.User,    http.StatusForbidden },
+		{ http.MethodGet,    "/api/storage/v1/trading-systems/abc/documentation", role.User, http.StatusBadRequest },
+	}
+
+	for _, test := range tests {
+		res := call(router, test.method, test.url, "john", test.role, nil)
+		if res.Code != test.status {
+			t.Errorf("%s %s as %s: expected %d, got %d", test.method, test.url, test.role, test.status, res.Code)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Documentation(t *testing.T) {
+	router := newTestRouter(t)
+
+	res := call(router, http.MethodPut, urlDoc, "john", role.User, map[string]string{ "documentation": "Trend following" })
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT documentation: got %d, %s", res.Code, res.Body.String())
+	}
+
+	tests := []struct {
+		user   string
+		status int
+		doc    string
+	}{
+		{ "john", http.StatusOK,                  "Trend following" },
+		{ "jane", http.StatusInternalServerError, ""                },
+	}
+
+	for _, test := range tests {
+		res = call(router, http.MethodGet, urlDoc, test.user, role.User, nil)
+		if res.Code != test.status {
+			t.Errorf("GET documentation as %s: expected %d, got %d", test.user, test.status, res.Code)
+			continue
+		}
+
+		if test.status == http.StatusOK {
+			doc := map[string]any{}
+			_ = json.Unmarshal(res.Body.Bytes(), &doc)
+
+			if doc["documentation"] != test.doc || doc["name"] != "Breakout" {
+				t.Errorf("GET documentation as %s: unexpected body %s", test.user, res.Body.String())
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_EquityChart(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := map[string]any{
+		"username": "john",
+		"images"  : map[string][]byte{ "daily": []byte("daily-png") },
+	}
+
+	res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `"status":"written"`) {
+		t.Fatalf("PUT equity-chart: got %d, %s", res.Code, res.Body.String())
+	}
+
+	//--- Per-type results are returned on failure too
+
+	body["images"] = map[string][]byte{ "daily": []byte("new-png"), "bad type": []byte("png") }
+
+	res = call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusBadRequest || !strings.Contains(res.Body.String(), `"status":"skipped"`) {
+		t.Fatalf("PUT equity-chart (invalid type): got %d, %s", res.Code, res.Body.String())
+	}
+
+	tests := []struct {
+		query       string
+		code        int
+		expected    string
+		placeholder string
+	}{
+		{ "?type=daily",                     http.StatusOK,       "daily-png",     ""     },
+		{ "?type=daily&fallback=default",    http.StatusOK,       "daily-png",     ""     },
+		{ "?type=weekly&fallback=default",   http.StatusOK,       "default-chart", "true" },
+		{ "?type=weekly",                    http.StatusNotFound, "",              ""     },
+	}
+
+	for _, test := range tests {
+		res = call(router, http.MethodGet, urlChart + test.query, "john", role.User, nil)
+		if res.Code != test.code || res.Code == http.StatusOK && res.Body.String() != test.expected {
+			t.Errorf("GET equity-chart%s: got %d, %q", test.query, res.Code, res.Body.String())
+		}
+		if ph := res.Header().Get(HeaderPlaceholder); ph != test.placeholder {
+			t.Errorf("GET equity-chart%s: bad placeholder header %q", test.query, ph)
+		}
+		if ct := res.Header().Get("Content-Type"); res.Code == http.StatusOK && ct != "image/png" {
+			t.Errorf("GET equity-chart%s: bad content type %s", test.query, ct)
+		}
+	}
+
+	res = call(router, http.MethodDelete, urlChart, "portfolio-trader", role.Service, map[string]any{ "username": "john" })
+	if res.Code != http.StatusOK {
+		t.Fatalf("DELETE equity-chart: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, urlChart +"?type=daily", "john", role.User, nil)
+	if res.Code != http.StatusNotFound {
+		t.Errorf("GET equity-chart after DELETE: expected 404, got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_DocumentationVersions(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, doc := range []string{ "first", "second" } {
+		res := call(router, http.MethodPut, urlDoc, "john", role.User, map[string]string{ "documentation": doc })
+		if res.Code != http.StatusOK {
+			t.Fatalf("PUT documentation: got %d", res.Code)
+		}
+	}
+
+	tests := []struct {
+		method string
+		url    string
+		status int
+	}{
+		{ http.MethodGet,  urlDoc +"/versions",           http.StatusOK         },
+		{ http.MethodGet,  urlDoc +"/versions/1",         http.StatusOK         },
+		{ http.MethodGet,  urlDoc +"/versions/5",         http.StatusNotFound   },
+		{ http.MethodGet,  urlDoc +"/versions/x",         http.StatusBadRequest },
+		{ http.MethodPost, urlDoc +"/versions/1/restore", http.StatusOK         },
+		{ http.MethodPost, urlDoc +"/versions/0/restore", http.StatusBadRequest },
+	}
+
+	for _, test := range tests {
+		res := call(router, test.method, test.url, "john", role.User, nil)
+		if res.Code != test.status {
+			t.Errorf("%s %s: expected %d, got %d", test.method, test.url, test.status, res.Code)
+		}
+	}
+
+	res := call(router, http.MethodGet, urlDoc +"/versions", "john", role.User, nil)
+
+	var list []map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &list)
+
+	if len(list) != 3 || list[2]["restoredFrom"] != float64(1) {
+		t.Errorf("GET versions: unexpected body %s", res.Body.String())
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_DocumentationIfMatch(t *testing.T) {
+	router := newTestRouter(t)
+
+	res := call(router, http.MethodGet, urlDoc, "john", role.User, nil)
+	etag := res.Header().Get("ETag")
+	if etag != buildContentETag(res.Body.Bytes()) {
+		t.Fatalf("GET documentation: bad ETag %q", etag)
+	}
+
+	body := map[string]string{ "documentation": "new" }
+
+	tests := []struct {
+		ifMatch string
+		status  int
+	}{
+		{ etag,      http.StatusOK                 },
+		{ etag,      http.StatusPreconditionFailed },
+		{ `W/"1"`,   http.StatusPreconditionFailed },
+		{ "garbage", http.StatusPreconditionFailed },
+		{ `"1"`,     http.StatusOK                 },
+		{ "*",       http.StatusOK                 },
+	}
+
+	for _, test := range tests {
+		res = call(router, http.MethodPut, urlDoc, "john", role.User, body, "If-Match", test.ifMatch)
+		if res.Code != test.status {
+			t.Errorf("PUT documentation with If-Match %s: expected %d, got %d", test.ifMatch, test.status, res.Code)
+		}
+
+		//--- The ETag of a write is the one of the next read
+		if test.status == http.StatusOK {
+			newEtag := res.Header().Get("ETag")
+			res = call(router, http.MethodGet, urlDoc, "john", role.User, nil)
+			if newEtag == "" || newEtag != res.Header().Get("ETag") {
+				t.Errorf("PUT documentation with If-Match %s: bad ETag %q", test.ifMatch, newEtag)
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_DocumentationDiff(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, doc := range []string{ "a\nb\n", "a\nc\n" } {
+		res := call(router, http.MethodPut, urlDoc, "john", role.User, map[string]string{ "documentation": doc })
+		if res.Code != http.StatusOK {
+			t.Fatalf("PUT documentation: got %d", res.Code)
+		}
+	}
+
+	tests := []struct {
+		query  string
+		status int
+		body   string
+	}{
+		{ "?from=1&to=2&format=text",       http.StatusOK,         "--- documentation.txt@1\n+++ documentation.txt@2\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n" },
+		{ "?from=2&format=text",            http.StatusOK,         "" },
+		{ "?from=1&to=current&format=text", http.StatusOK,         "--- documentation.txt@1\n+++ documentation.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n" },
+		{ "?from=x",                        http.StatusBadRequest, "" },
+		{ "?from=1&to=9",                   http.StatusNotFound,   "" },
+	}
+
+	for _, test := range tests {
+		res := call(router, http.MethodGet, urlDoc +"/diff"+ test.query, "john", role.User, nil)
+		if res.Code != test.status {
+			t.Errorf("GET diff%s: expected %d, got %d", test.query, test.status, res.Code)
+		}
+		if test.status == http.StatusOK && res.Body.String() != test.body {
+			t.Errorf("GET diff%s: got %q", test.query, res.Body.String())
+		}
+	}
+
+	res := call(router, http.MethodGet, urlDoc +"/diff?from=1&to=2", "john", role.User, nil)
+
+	diff := map[string]any{}
+	_ = json.Unmarshal(res.Body.Bytes(), &diff)
+
+	if hunks, ok := diff["hunks"].([]any); !ok || len(hunks) != 1 {
+		t.Errorf("GET diff (json): unexpected body %s", res.Body.String())
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Code(t *testing.T) {
+	router := newTestRouter(t)
+	urlCode := "/api/storage/v1/trading-systems/1/code"
+
+	tests := []struct {
+		method string
+		url    string
+		user   string
+		body   any
+		status int
+	}{
+		{ http.MethodGet,    urlCode,                  "john", nil,                          http.StatusOK                    },
+		{ http.MethodPut,    urlCode +"/breakout.el",  "john", []byte("inputs: len(20);"),   http.StatusOK                    },
+		{ http.MethodPut,    urlCode +"/strategy.py",  "john", []byte("print('hi')"),        http.StatusOK                    },
+		{ http.MethodPut,    urlCode +"/big.py",       "john", bytes.Repeat([]byte("x"), 2 << 20), http.StatusRequestEntityTooLarge },
+		{ http.MethodPut,    urlCode +"/.hidden",      "john", []byte("x"),                  http.StatusBadRequest            },
+		{ http.MethodPut,    urlCode +"/breakout.el",  "jane", []byte("x"),                  http.StatusNotFound              },
+		{ http.MethodGet,    urlCode +"/breakout.el",  "jane", nil,                          http.StatusNotFound              },
+		{ http.MethodDelete, urlCode +"/strategy.py",  "john", nil,                          http.StatusOK                    },
+		{ http.MethodDelete, urlCode +"/strategy.py",  "john", nil,                          http.StatusNotFound              },
+	}
+
+	for _, test := range tests {
+		res := call(router, test.method, test.url, test.user, role.User, test.body)
+		if res.Code != test.status {
+			t.Errorf("%s %s as %s: expected %d, got %d", test.method, test.url, test.user, test.status, res.Code)
+		}
+	}
+
+	res := call(router, http.MethodGet, urlCode +"/breakout.el", "john", role.User, nil)
+	if res.Body.String() != "inputs: len(20);" || res.Header().Get("Content-Type") != "text/x-easylanguage; charset=utf-8" {
+		t.Errorf("GET code file: got %q (%s)", res.Body.String(), res.Header().Get("Content-Type"))
+	}
+	if res.Header().Get("Content-Disposition") != "attachment; filename=breakout.el" {
+		t.Errorf("GET code file: bad Content-Disposition %q", res.Header().Get("Content-Disposition"))
+	}
+
+	res = call(router, http.MethodGet, urlCode, "john", role.User, nil)
+
+	var list []map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &list)
+
+	if len(list) != 1 || list[0]["name"] != "breakout.el" || list[0]["size"] != float64(16) {
+		t.Errorf("GET code: unexpected body %s", res.Body.String())
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_CodeRevisions(t *testing.T) {
+	router := newTestRouter(t)
+	urlFile := "/api/storage/v1/trading-systems/1/code/main.el"
+
+	for _, code := range []string{ "v1", "v2" } {
+		res := call(router, http.MethodPut, urlFile, "john", role.User, []byte(code))
+		if res.Code != http.StatusOK {
+			t.Fatalf("PUT code: got %d", res.Code)
+		}
+	}
+
+	tests := []struct {
+		method string
+		url    string
+		body   any
+		status int
+	}{
+		{ http.MethodGet,  urlFile +"/revisions",           nil,                                      http.StatusOK         },
+		{ http.MethodGet,  urlFile +"/revisions/1",         nil,                                      http.StatusOK         },
+		{ http.MethodGet,  urlFile +"/revisions/3",         nil,                                      http.StatusNotFound   },
+		{ http.MethodPost, urlFile +"/revisions/1/tags",    map[string]string{ "tag": "live-2026-09" }, http.StatusOK         },
+		{ http.MethodPost, urlFile +"/revisions/2/tags",    map[string]string{ "tag": "live-2026-09" }, http.StatusConflict   },
+		{ http.MethodPost, urlFile +"/revisions/2/tags",    map[string]string{},                      http.StatusBadRequest },
+		{ http.MethodGet,  urlFile +"/deployment",          nil,                                      http.StatusNotFound   },
+		{ http.MethodPut,  urlFile +"/deployment",          map[string]int{ "revision": 1 },          http.StatusOK         },
+		{ http.MethodGet,  urlFile +"/deployment",          nil,                                      http.StatusOK         },
+		{ http.MethodPost, urlFile +"/revisions/1/restore", nil,                                      http.StatusOK         },
+	}
+
+	for _, test := range tests {
+		res := call(router, test.method, test.url, "john", role.User, test.body)
+		if res.Code != test.status {
+			t.Errorf("%s %s: expected %d, got %d (%s)", test.method, test.url, test.status, res.Code, res.Body.String())
+		}
+	}
+
+	res := call(router, http.MethodGet, urlFile, "john", role.User, nil)
+	if res.Body.String() != "v1" {
+		t.Errorf("GET code after restore: got %q", res.Body.String())
+	}
+}
+
+//=============================================================================
+
+func newMultipart(t *testing.T, fields map[string]string, fileName string, content []byte) (*bytes.Buffer, string) {
+	body   := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for k, v := range fields {
+		_ = writer.WriteField(k, v)
+	}
+
+	if fileName != "" {
+		part, err := writer.CreateFormFile("file", fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = part.Write(content)
+	}
+
+	_ = writer.Close()
+
+	return body, writer.FormDataContentType()
+}
+
+//=============================================================================
+
+func TestRoutes_Reports(t *testing.T) {
+	router := newTestRouter(t)
+	urlReports := "/api/storage/v1/trading-systems/1/reports"
+
+	upload := func(name string, fields map[string]string, content []byte) *httptest.ResponseRecorder {
+		body, contentType := newMultipart(t, fields, name, content)
+
+		request := httptest.NewRequest(http.MethodPut, urlReports +"/"+ name, body)
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set(headerUser, "john")
+		request.Header.Set(headerRole, string(role.User))
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	res := upload("backtest.html", map[string]string{ "title": "Backtest", "tool": "TradeStation" }, []byte("<html></html>"))
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT report: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = upload("backtest.pdf", map[string]string{}, []byte("%PDF-1.4"))
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("PUT report without title: expected 400, got %d", res.Code)
+	}
+
+	res = upload("huge.pdf", map[string]string{ "title": "Huge" }, append([]byte("%PDF-1.4"), make([]byte, 21 << 20)...))
+	if res.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("PUT huge report: expected 413, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, urlReports +"/backtest.html", "john", role.User, nil)
+	if res.Code != http.StatusOK || res.Header().Get("X-Content-Type-Options") != "nosniff" ||
+		res.Header().Get("Content-Disposition") != "attachment; filename=backtest.html" {
+		t.Errorf("GET report: got %d, headers %v", res.Code, res.Header())
+	}
+
+	res = call(router, http.MethodGet, urlReports, "john", role.User, nil)
+
+	var list []map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &list)
+
+	if len(list) != 1 || list[0]["title"] != "Backtest" || list[0]["tool"] != "TradeStation" {
+		t.Errorf("GET reports: unexpected body %s", res.Body.String())
+	}
+
+	res = call(router, http.MethodDelete, urlReports +"/backtest.html", "john", role.User, nil)
+	if res.Code != http.StatusOK {
+		t.Errorf("DELETE report: got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Images(t *testing.T) {
+	router := newTestRouter(t)
+	urlImages := "/api/storage/v1/trading-systems/1/images"
+
+	buf := &bytes.Buffer{}
+	_ = png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 8, 6)))
+
+	res := call(router, http.MethodPut, urlImages +"/setup.png", "john", role.User, buf.Bytes())
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT image: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodPut, urlImages +"/setup.png", "john", role.User, []byte("<script>"))
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("PUT invalid image: expected 400, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, urlImages +"/setup.png", "john", role.User, nil)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "image/png" ||
+		res.Header().Get("X-Content-Type-Options") != "nosniff" || !bytes.Equal(res.Body.Bytes(), buf.Bytes()) {
+		t.Errorf("GET image: got %d, headers %v", res.Code, res.Header())
+	}
+
+	res = call(router, http.MethodGet, urlImages, "john", role.User, nil)
+
+	var list []map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &list)
+
+	if len(list) != 1 || list[0]["width"] != 8.0 || list[0]["height"] != 6.0 {
+		t.Errorf("GET images: unexpected body %s", res.Body.String())
+	}
+
+	res = call(router, http.MethodDelete, urlImages +"/setup.png", "john", role.User, nil)
+	if res.Code != http.StatusOK {
+		t.Errorf("DELETE image: got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_EquityChartList(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := map[string]any{
+		"username": "john",
+		"images"  : map[string][]byte{ "daily": []byte("daily-png"), "weekly": []byte("weekly-png") },
+		"metadata": map[string]any{
+			"daily": map[string]any{ "generatedAt": "2025-03-01T18:00:00Z", "trades": 42, "producerVersion": "2.1.0" },
+		},
+	}
+
+	res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT equity-chart: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, urlChart +"s", "john", role.User, nil)
+
+	var list []map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &list)
+
+	if res.Code != http.StatusOK || len(list) != 2 {
+		t.Fatalf("GET equity-charts: got %d, %s", res.Code, res.Body.String())
+	}
+
+	//--- sha256("daily-png")
+	if list[0]["type"] != "daily" || list[0]["size"] != 9.0 ||
+		list[0]["hash"] != "53ff04070be70e0c7ff0d9c0b671e1904cf1f1d1ff789334820cc102102e36b7" {
+		t.Errorf("GET equity-charts: bad entry %v", list[0])
+	}
+	if list[1]["type"] != "weekly" || list[0]["hash"] == list[1]["hash"] {
+		t.Errorf("GET equity-charts: bad entry %v", list[1])
+	}
+
+	res = call(router, http.MethodGet, urlChart +"/metadata?type=daily", "john", role.User, nil)
+
+	var info map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &info)
+
+	meta, _ := info["metadata"].(map[string]any)
+	if res.Code != http.StatusOK || meta == nil || meta["generatedAt"] != "2025-03-01T18:00:00Z" || meta["trades"] != 42.0 {
+		t.Errorf("GET equity-chart/metadata: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, urlChart +"/metadata?type=monthly", "john", role.User, nil)
+	if res.Code != http.StatusNotFound {
+		t.Errorf("GET equity-chart/metadata (missing chart): expected 404, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/trading-systems/2/equity-charts", "john", role.User, nil)
+	if res.Code != http.StatusNotFound {
+		t.Errorf("GET equity-charts (missing trading system): expected 404, got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Caching(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := map[string]any{
+		"username": "john",
+		"images"  : map[string][]byte{ "daily": []byte("daily-png") },
+	}
+
+	res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT equity-chart: got %d, %s", res.Code, res.Body.String())
+	}
+
+	urls := []string{
+		urlChart +"?type=daily",
+		urlDoc,
+	}
+
+	for _, url := range urls {
+		res = call(router, http.MethodGet, url, "john", role.User, nil)
+		etag := res.Header().Get("ETag")
+		lm   := res.Header().Get("Last-Modified")
+
+		if res.Code != http.StatusOK || etag == "" || lm == "" || res.Header().Get("Cache-Control") != "private, no-cache" {
+			t.Fatalf("GET %s: got %d, headers %v", url, res.Code, res.Header())
+		}
+
+		res = call(router, http.MethodGet, url, "john", role.User, nil, "If-None-Match", etag)
+		if res.Code != http.StatusNotModified || res.Body.Len() != 0 || res.Header().Get("ETag") != etag {
+			t.Errorf("GET %s with If-None-Match: got %d, %q", url, res.Code, res.Body.String())
+		}
+
+		res = call(router, http.MethodGet, url, "john", role.User, nil, "If-Modified-Since", lm)
+		if res.Code != http.StatusNotModified {
+			t.Errorf("GET %s with If-Modified-Since: got %d", url, res.Code)
+		}
+
+		res = call(router, http.MethodGet, url, "john", role.User, nil, "If-None-Match", `"stale"`, "If-Modified-Since", lm)
+		if res.Code != http.StatusOK {
+			t.Errorf("GET %s with stale If-None-Match: got %d", url, res.Code)
+		}
+	}
+
+	//--- sha256("daily-png")
+	res = call(router, http.MethodGet, urlChart +"?type=daily", "john", role.User, nil)
+	if etag := res.Header().Get("ETag"); etag != `"53ff04070be70e0c7ff0d9c0b671e1904cf1f1d1ff789334820cc102102e36b7"` {
+		t.Errorf("GET equity-chart: bad ETag %s", etag)
+	}
+
+	res = call(router, http.MethodPut, urlDoc, "john", role.User, map[string]any{ "documentation": "changed" })
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT documentation: got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, urlDoc, "john", role.User, nil)
+	etag := res.Header().Get("ETag")
+
+	//--- A rename changes the body, hence the ETag
+	err := backend.UpdateTradingSystem(&backend.TradingSystem{ Id: 1, Username: "john", Name: "Renamed" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res = call(router, http.MethodGet, urlDoc, "john", role.User, nil, "If-None-Match", etag)
+	if res.Code != http.StatusOK || res.Header().Get("ETag") == etag || !strings.Contains(res.Body.String(), "Renamed") {
+		t.Errorf("GET documentation after rename: got %d, ETag %s", res.Code, res.Header().Get("ETag"))
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Thumbnails(t *testing.T) {
+	router := newTestRouter(t)
+
+	buf := &bytes.Buffer{}
+	_ = png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 200, 100)))
+
+	body := map[string]any{
+		"username": "john",
+		"images"  : map[string][]byte{ "daily": buf.Bytes() },
+	}
+
+	res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT equity-chart: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodPut, "/api/storage/v1/trading-systems/1/images/setup.png", "john", role.User, buf.Bytes())
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT image: got %d, %s", res.Code, res.Body.String())
+	}
+
+	tests := []struct {
+		url    string
+		code   int
+		width  int
+		height int
+	}{
+		{ urlChart +"?type=daily&width=50",                        http.StatusOK,         50, 25 },
+		{ urlChart +"?type=daily&width=50&height=50&fit=fill",     http.StatusOK,         50, 50 },
+		{ urlChart +"?type=daily&width=abc",                       http.StatusBadRequest, 0,  0  },
+		{ urlChart +"?type=daily&fit=cover",                       http.StatusBadRequest, 0,  0  },
+		{ "/api/storage/v1/trading-systems/1/images/setup.png?height=10", http.StatusOK,  20, 10 },
+	}
+
+	for _, test := range tests {
+		res = call(router, http.MethodGet, test.url, "john", role.User, nil)
+		if res.Code != test.code {
+			t.Errorf("GET %s: expected %d, got %d", test.url, test.code, res.Code)
+			continue
+		}
+
+		if test.code == http.StatusOK {
+			cfg, err := png.DecodeConfig(bytes.NewReader(res.Body.Bytes()))
+			if err != nil || cfg.Width != test.width || cfg.Height != test.height {
+				t.Errorf("GET %s: got %dx%d, %v", test.url, cfg.Width, cfg.Height, err)
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_EquityData(t *testing.T) {
+	router := newTestRouter(t)
+	urlData := "/api/storage/v1/trading-systems/1/equity-data"
+
+	body := map[string]any{
+		"username": "john",
+		"format"  : "csv",
+		"series"  : map[string]string{ "daily": "timestamp,equity\n2024-01-01,100\n2024-01-02,120\n" },
+	}
+
+	res := call(router, http.MethodPut, urlData, "john", role.User, body)
+	if res.Code != http.StatusForbidden {
+		t.Errorf("PUT equity-data as user: expected 403, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodPut, urlData, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT equity-data: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, urlData +"?type=daily&format=csv", "john", role.User, nil)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("GET equity-data: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, urlData +"/chart?type=daily&width=400&height=200", "john", role.User, nil)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("GET equity-data/chart: got %d, %s", res.Code, res.Body.String())
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(res.Body.Bytes()))
+	if err != nil || cfg.Width != 400 || cfg.Height != 200 {
+		t.Errorf("GET equity-data/chart: got %dx%d, %v", cfg.Width, cfg.Height, err)
+	}
+
+	res = call(router, http.MethodGet, urlData +"/chart?type=daily&format=svg", "john", role.User, nil)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Errorf("GET equity-data/chart (svg): got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, urlData +"/chart?type=daily&width=x", "john", role.User, nil)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("GET equity-data/chart (bad width): expected 400, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodDelete, urlData, "portfolio-trader", role.Service, map[string]any{ "username": "john" })
+	if res.Code != http.StatusOK {
+		t.Errorf("DELETE equity-data: got %d, %s", res.Code, res.Body.String())
+	}
+}
+
+//=============================================================================
+
+func TestNegotiateChartFormats(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{ "image/png",                                      "png"          },
+		{ "image/svg+xml, image/png;q=0.5",                 "svg,png"      },
+		{ "image/png;q=0.5, image/svg+xml",                 "svg,png"      },
+		{ "*/*",                                            "png,svg,webp" },
+		{ "image/webp, image/*;q=0.8",                      "webp,png,svg" },
+		{ "image/*, image/webp;q=0",                        "png,svg"      },
+		{ "application/json, text/plain, */*",              "png,svg,webp" },
+		{ "application/json",                               ""             },
+	}
+
+	for _, test := range tests {
+		if res := strings.Join(negotiateChartFormats(test.accept), ","); res != test.expected {
+			t.Errorf("negotiateChartFormats(%q): expected %q, got %q", test.accept, test.expected, res)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_EquityChartFormats(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, data := range []string{ "daily-png", `<svg xmlns="http://www.w3.org/2000/svg"/>` } {
+		body := map[string]any{
+			"username": "john",
+			"images"  : map[string][]byte{ "daily": []byte(data) },
+		}
+
+		res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+		if res.Code != http.StatusOK {
+			t.Fatalf("PUT equity-chart: got %d, %s", res.Code, res.Body.String())
+		}
+	}
+
+	tests := []struct {
+		query       string
+		accept      string
+		code        int
+		contentType string
+	}{
+		{ "",            "",                             http.StatusOK,            "image/png"     },
+		{ "",            "image/png",                    http.StatusOK,            "image/png"     },
+		{ "&format=png", "",                             http.StatusOK,            "image/png"     },
+		{ "",            "image/svg+xml, image/*;q=0.1", http.StatusOK,            "image/svg+xml" },
+		{ "&format=svg", "image/png",                    http.StatusOK,            "image/svg+xml" },
+		{ "",            "image/webp",                   http.StatusNotAcceptable, ""              },
+		{ "",            "application/json",             http.StatusNotAcceptable, ""              },
+		{ "&format=gif", "",                             http.StatusBadRequest,    ""              },
+	}
+
+	for _, test := range tests {
+		var headers []string
+		if test.accept != "" {
+			headers = []string{ "Accept", test.accept }
+		}
+
+		res := call(router, http.MethodGet, urlChart +"?type=daily"+ test.query, "john", role.User, nil, headers...)
+		if res.Code != test.code {
+			t.Errorf("GET equity-chart%s (%s): expected %d, got %d", test.query, test.accept, test.code, res.Code)
+			continue
+		}
+
+		if test.code == http.StatusOK {
+			if ct := res.Header().Get("Content-Type"); ct != test.contentType {
+				t.Errorf("GET equity-chart%s (%s): got %s", test.query, test.accept, ct)
+			}
+			if test.contentType == "image/svg+xml" && res.Header().Get("Content-Security-Policy") == "" {
+				t.Errorf("GET equity-chart (svg): missing Content-Security-Policy")
+			}
+			if res.Header().Get("Vary") != "Accept" {
+				t.Errorf("GET equity-chart: missing Vary header")
+			}
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_EquityChartBatch(t *testing.T) {
+	router   := newTestRouter(t)
+	urlBatch := "/api/storage/v1/equity-charts?type=daily&ids=1,2"
+
+	body := map[string]any{
+		"username": "john",
+		"images"  : map[string][]byte{ "daily": []byte("daily-png") },
+	}
+
+	res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusOK {
+		t.Fatalf("PUT equity-chart: got %d, %s", res.Code, res.Body.String())
+	}
+
+	//--- Zip
+
+	res = call(router, http.MethodGet, urlBatch, "john", role.User, nil)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("GET batch (zip): got %d, %s", res.Code, res.Body.String())
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(res.Body.Bytes()), int64(res.Body.Len()))
+	if err != nil || len(zr.File) != 2 || zr.File[0].Name != "1-daily-equity-chart.png" || zr.File[1].Name != BatchManifest {
+		t.Fatalf("GET batch (zip): bad archive, %v", err)
+	}
+
+	var manifest []map[string]any
+	rc, _ := zr.File[1].Open()
+	_ = json.NewDecoder(rc).Decode(&manifest)
+
+	if len(manifest) != 2 || manifest[0]["status"] != 200.0 || manifest[1]["status"] != 404.0 {
+		t.Errorf("GET batch (zip): bad manifest %v", manifest)
+	}
+
+	//--- Multipart
+
+	res = call(router, http.MethodGet, urlBatch, "john", role.User, nil, "Accept", "multipart/mixed")
+	mediaType, params, err := mime.ParseMediaType(res.Header().Get("Content-Type"))
+	if res.Code != http.StatusOK || err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("GET batch (multipart): got %d, %s", res.Code, res.Header().Get("Content-Type"))
+	}
+
+	mr := multipart.NewReader(res.Body, params["boundary"])
+
+	part, err := mr.NextPart()
+	if err != nil || part.Header.Get("X-Trading-System-Id") != "1" || part.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("GET batch (multipart): bad first part %v, %v", part, err)
+	}
+	if data, _ := io.ReadAll(part); string(data) != "daily-png" {
+		t.Errorf("GET batch (multipart): bad chart %q", data)
+	}
+
+	part, err = mr.NextPart()
+	if err != nil || part.FileName() != BatchManifest {
+		t.Errorf("GET batch (multipart): missing manifest, %v", err)
+	}
+
+	for _, url := range []string{
+		"/api/storage/v1/equity-charts?type=daily",
+		"/api/storage/v1/equity-charts?type=daily&ids=1,x",
+		"/api/storage/v1/equity-charts?type=daily&ids=1&archive=tar",
+	} {
+		res = call(router, http.MethodGet, url, "john", role.User, nil)
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: expected 400, got %d", url, res.Code)
+		}
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_EquityChartHistory(t *testing.T) {
+	newTestRouter(t)
+
+	router := gin.New()
+	registerRoutes(router, fakeSecure(&app.Config{ Storage: app.Storage{ ChartHistory: 5 } }))
+
+	for _, chart := range []string{ "daily-v1", "daily-v2" } {
+		body := map[string]any{
+			"username": "john",
+			"images"  : map[string][]byte{ "daily": []byte(chart) },
+		}
+
+		res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+		if res.Code != http.StatusOK {
+			t.Fatalf("PUT equity-chart: got %d, %s", res.Code, res.Body.String())
+		}
+	}
+
+	res := call(router, http.MethodGet, urlChart +"/history?type=daily", "john", role.User, nil)
+
+	var list []map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &list)
+
+	if res.Code != http.StatusOK || len(list) != 2 || list[0]["format"] != "png" {
+		t.Fatalf("GET equity-chart/history: got %d, %s", res.Code, res.Body.String())
+	}
+
+	timestamp := list[0]["timestamp"].(string)
+
+	res = call(router, http.MethodGet, urlChart +"/history/"+ url.PathEscape(timestamp) +"?type=daily", "john", role.User, nil)
+	if res.Code != http.StatusOK || res.Body.String() != "daily-v1" || res.Header().Get("ETag") == "" {
+		t.Errorf("GET equity-chart/history/%s: got %d, %s", timestamp, res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, urlChart +"/history/yesterday?type=daily", "john", role.User, nil)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("GET equity-chart/history (bad timestamp): expected 400, got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_PathTraversal(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, query := range []string{ "?type=../../jane/1/x", "?type=..%2F..%2Fx", "?type=a%00b" } {
+		res := call(router, http.MethodGet, urlChart + query, "john", role.User, nil)
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("GET equity-chart%s: expected 400, got %d", query, res.Code)
+		}
+	}
+
+	body := map[string]any{
+		"username": "../jane",
+		"images"  : map[string][]byte{ "daily": []byte("daily-png") },
+	}
+
+	res := call(router, http.MethodPut, urlChart, "portfolio-trader", role.Service, body)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("PUT equity-chart (bad username): expected 400, got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, urlChart, "..", role.User, nil)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("GET equity-chart (bad session username): expected 400, got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Trash(t *testing.T) {
+	router := newTestRouter(t)
+
+	if err := backend.DeleteTradingSystem(1, "john"); err != nil {
+		t.Fatal(err)
+	}
+
+	res := call(router, http.MethodGet, "/api/storage/v1/admin/trash", "john", role.User, nil)
+	if res.Code != http.StatusForbidden {
+		t.Errorf("GET admin/trash (user): expected 403, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/admin/trash?username=john", "admin", role.Admin, nil)
+
+	var list []map[string]any
+	_ = json.Unmarshal(res.Body.Bytes(), &list)
+
+	if res.Code != http.StatusOK || len(list) != 1 || list[0]["name"] != "Breakout" {
+		t.Fatalf("GET admin/trash: got %d, %s", res.Code, res.Body.String())
+	}
+
+	urlRestore := "/api/storage/v1/admin/trash/john/"+ list[0]["entry"].(string) +"/restore"
+
+	res = call(router, http.MethodPost, urlRestore, "admin", role.Admin, nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("POST restore: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/trading-systems/1/documentation", "john", role.User, nil)
+	if res.Code != http.StatusOK {
+		t.Errorf("GET documentation after restore: got %d", res.Code)
+	}
+
+	res = call(router, http.MethodPost, urlRestore, "admin", role.Admin, nil)
+	if res.Code != http.StatusNotFound {
+		t.Errorf("POST restore (twice): expected 404, got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Backup(t *testing.T) {
+	router := newTestRouter(t)
+
+	res := call(router, http.MethodGet, "/api/storage/v1/admin/backups/john", "john", role.User, nil)
+	if res.Code != http.StatusForbidden {
+		t.Errorf("GET admin/backups (user): expected 403, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/admin/backups/john?format=rar", "admin", role.Admin, nil)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("GET admin/backups (bad format): expected 400, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/admin/backups/john?format=zip", "admin", role.Admin, nil)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("GET admin/backups: got %d, %s", res.Code, res.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(res.Header().Get("Content-Disposition"), "filename=john-") {
+		t.Errorf("GET admin/backups: bad disposition %s", res.Header().Get("Content-Disposition"))
+	}
+
+	archive := res.Body.Bytes()
+	urlRestore := "/api/storage/v1/admin/backups/john/restore?format=zip"
+
+	res = call(router, http.MethodPost, urlRestore, "admin", role.Admin, archive)
+	if res.Code != http.StatusConflict {
+		t.Errorf("POST restore (existing): expected 409, got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodPost, urlRestore +"&remap=1:2", "admin", role.Admin, archive)
+
+	result := map[string]any{}
+	_ = json.Unmarshal(res.Body.Bytes(), &result)
+
+	if res.Code != http.StatusOK || result["files"] != float64(2) {
+		t.Fatalf("POST restore: got %d, %s", res.Code, res.Body.String())
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/trading-systems/2/documentation", "john", role.User, nil)
+	if res.Code != http.StatusOK {
+		t.Errorf("GET documentation after restore: got %d", res.Code)
+	}
+
+	res = call(router, http.MethodPost, urlRestore +"&remap=1:3", "admin", role.Admin, []byte("garbage"))
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("POST restore (garbage): expected 400, got %d", res.Code)
+	}
+}
+
+//=============================================================================
+
+func TestRoutes_Scrub(t *testing.T) {
+	router := newTestRouter(t)
+
+	res := call(router, http.MethodGet, "/api/storage/v1/admin/scrub", "john", role.User, nil)
+	if res.Code != http.StatusForbidden {
+		t.Errorf("GET admin/scrub (user): expected 403, got %d", res.Code)
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/admin/scrub", "admin", role.Admin, nil)
+	if res.Code != http.StatusNotFound {
+		t.Errorf("GET admin/scrub (never run): expected 404, got %d", res.Code)
+	}
+
+	if _, err := backend.Scrub(); err != nil {
+		t.Fatal(err)
+	}
+
+	res = call(router, http.MethodGet, "/api/storage/v1/admin/scrub", "admin", role.Admin, nil)
+
+	report := map[string]any{}
+	_ = json.Unmarshal(res.Body.Bytes(), &report)
+
+	if res.Code != http.StatusOK || report["tradingSystems"] != float64(1) || report["issues"] == nil {
+		t.Errorf("GET admin/scrub: got %d, %s", res.Code, res.Body.String())
+	}
+}
+
+//=============================================================================
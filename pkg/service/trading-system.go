@@ -25,8 +25,26 @@ THE SOFTWARE.
 package service
 
 import (
+	"encoding/json"
 	"github.com/bit-fever/core/auth"
+	"github.com/bit-fever/core/req"
+	"github.com/bit-fever/storage-manager/pkg/backend"
 	"github.com/bit-fever/storage-manager/pkg/business"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//=============================================================================
+
+const (
+	FallbackDefault   = "default"
+	HeaderPlaceholder = "X-Placeholder"
+
+	jsonContentType   = "application/json; charset=utf-8"
 )
 
 //=============================================================================
@@ -35,10 +53,13 @@ func getDocumentation(c *auth.Context) {
 	tsId, err := c.GetIdFromUrl()
 
 	if err == nil {
-		var res *business.DocumentationResponse
-		res,err = business.GetDocumentation(c, tsId)
+		var data []byte
+		var res  *business.DocumentationResponse
+		res, data, err = getDocumentationBody(c, tsId)
 		if err == nil {
-			_ = c.ReturnObject(res)
+			if !checkNotModified(c, buildContentETag(data), res.LastModified) {
+				_ = c.ReturnData(jsonContentType, data)
+			}
 			return
 		}
 	}
@@ -56,9 +77,58 @@ func setDocumentation(c *auth.Context) {
 		err = c.BindParamsFromBody(&docReq)
 
 		if err == nil {
-			err = business.SetDocumentation(c, tsId, &docReq)
+			var expRev int
+			expRev, err = getExpectedRevision(c, tsId)
+
 			if err == nil {
-				_ = c.ReturnObject("")
+				_, err = business.SetDocumentation(c, tsId, &docReq, expRev)
+				if err == nil {
+					var data []byte
+					_, data, err = getDocumentationBody(c, tsId)
+					if err == nil {
+						c.Gin.Header("ETag", buildContentETag(data))
+						_ = c.ReturnObject("")
+						return
+					}
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getDocumentationVersions(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var res []*backend.Revision
+		res,err = business.GetDocumentationVersions(c, tsId)
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getDocumentationVersion(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var rev int
+		rev, err = getRevisionFromUrl(c)
+
+		if err == nil {
+			var res *business.DocumentationVersionResponse
+			res,err = business.GetDocumentationVersion(c, tsId, rev)
+			if err == nil {
+				_ = c.ReturnObject(res)
 				return
 			}
 		}
@@ -69,14 +139,172 @@ func setDocumentation(c *auth.Context) {
 
 //=============================================================================
 
+func restoreDocumentationVersion(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var rev int
+		rev, err = getRevisionFromUrl(c)
+
+		if err == nil {
+			var res *backend.Revision
+			res,err = business.RestoreDocumentationVersion(c, tsId, rev)
+			if err == nil {
+				_ = c.ReturnObject(res)
+				return
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getDocumentationDiff(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var from, to int
+		from, err = getRevisionFromQuery(c, "from")
+
+		if err == nil {
+			to, err = getRevisionFromQuery(c, "to")
+
+			if err == nil {
+				var res *business.DocumentationDiffResponse
+				res, err = business.DiffDocumentation(c, tsId, from, to)
+				if err == nil {
+					if c.GetParamAsString("format", "json") == "text" {
+						_ = c.ReturnData("text/x-diff; charset=utf-8", []byte(res.Unified))
+					} else {
+						_ = c.ReturnObject(res)
+					}
+					return
+				}
+			}
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getEquityCharts(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		var res []*business.EquityChartInfo
+		res, err = business.GetEquityCharts(c, tsId)
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getEquityChartMetadata(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		chartType := c.GetParamAsString("type",   "")
+		format    := c.GetParamAsString("format", "")
+
+		var res *business.EquityChartInfo
+		res, err = business.GetEquityChartMetadata(c, tsId, chartType, format)
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
+func getEquityChartSnapshots(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		chartType := c.GetParamAsString("type", "")
+
+		var res []*business.EquityChartSnapshot
+		res, err = business.GetEquityChartSnapshots(c, tsId, chartType)
+		if err == nil {
+			_ = c.ReturnObject(res)
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+//=== Snapshots never change, so the timestamp is enough to validate them
+
+func getEquityChartSnapshot(c *auth.Context) {
+	tsId, err := c.GetIdFromUrl()
+
+	if err == nil {
+		chartType := c.GetParamAsString("type", "")
+
+		var timestamp time.Time
+		timestamp, err = time.Parse(time.RFC3339Nano, c.Gin.Param("timestamp"))
+		if err != nil {
+			err = req.AppError{ Code: http.StatusBadRequest, Message: "Invalid snapshot timestamp: "+ c.Gin.Param("timestamp") }
+		}
+
+		var ec *business.EquityChart
+		if err == nil {
+			ec, err = business.GetEquityChartSnapshot(c, tsId, chartType, timestamp)
+		}
+		if err == nil {
+			if !checkNotModified(c, `"`+ ec.Hash +`"`, ec.ModTime) {
+				returnChart(c, ec)
+			}
+			return
+		}
+	}
+
+	c.ReturnError(err)
+}
+
+//=============================================================================
+
 func getEquityChart(c *auth.Context) {
 	tsId, err := c.GetIdFromUrl()
 	if err == nil {
 		chartType := c.GetParamAsString("type", "unknown")
-		var data []byte
-		data,err = business.GetEquityChart(c, tsId, chartType)
+		fallback  := c.Gin.Query("fallback") == FallbackDefault
+		var rr *business.ResizeRequest
+		rr,err = getResizeRequest(c)
+
+		var formats []string
+		if err == nil {
+			formats,err = getChartFormats(c)
+		}
+
+		var ec *business.EquityChart
 		if err == nil {
-			_ = c.ReturnData("image/png", data)
+			c.Gin.Header("Vary", "Accept")
+			ec,err = business.GetEquityChart(c, tsId, chartType, formats, fallback)
+		}
+		if err == nil && rr != nil {
+			err = business.ResizeEquityChart(c, tsId, ec, rr)
+		}
+		if err == nil {
+			if ec.Placeholder {
+				c.Gin.Header(HeaderPlaceholder, "true")
+			}
+			if !checkNotModified(c, `"`+ ec.Hash +`"`, ec.ModTime) {
+				returnChart(c, ec)
+			}
 			return
 		}
 	}
@@ -94,9 +322,15 @@ func setEquityCharts(c *auth.Context) {
 		err = c.BindParamsFromBody(equReq)
 
 		if err == nil {
-			err = business.SetEquityCharts(c, tsId, equReq)
+			var res *business.EquityResponse
+			res, err = business.SetEquityCharts(c, tsId, equReq)
 			if err == nil {
-				_ = c.ReturnObject("")
+				_ = c.ReturnObject(res)
+				return
+			}
+
+			if res != nil {
+				returnErrorWithResult(c, err, res)
 				return
 			}
 		}
@@ -127,3 +361,200 @@ func deleteEquityCharts(c *auth.Context) {
 }
 
 //=============================================================================
+//===
+//=== Private functions
+//===
+//=============================================================================
+
+func getRevisionFromUrl(c *auth.Context) (int, error) {
+	sRev := c.Gin.Param("rev")
+	rev, err := strconv.Atoi(sRev)
+
+	if err != nil || rev < 1 {
+		return 0, req.NewBadRequestError("Invalid revision in url: %v", sRev)
+	}
+
+	return rev, nil
+}
+
+//=============================================================================
+//=== A missing parameter or 'current' refer to the current documentation
+
+func getRevisionFromQuery(c *auth.Context, name string) (int, error) {
+	sRev := c.GetParamAsString(name, "current")
+	if sRev == "current" {
+		return business.CurrentDocumentation, nil
+	}
+
+	rev, err := strconv.Atoi(sRev)
+	if err != nil || rev < 1 {
+		return 0, req.NewBadRequestError("Invalid revision in parameter '"+ name +"': %v", sRev)
+	}
+
+	return rev, nil
+}
+
+//=============================================================================
+//=== The ETag of the documentation is the hash of the JSON body, which also
+//=== holds the name of the trading system
+
+func getDocumentationBody(c *auth.Context, id uint) (*business.DocumentationResponse, []byte, error) {
+	res, err := business.GetDocumentation(c, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil, nil, req.NewServerErrorByError(err)
+	}
+
+	return res, data, nil
+}
+
+//=============================================================================
+//=== Extracts the revision from the If-Match header, which holds either the
+//=== ETag of the documentation or a revision number. Writes without the
+//=== header (or with '*') are unconditional.
+
+func getExpectedRevision(c *auth.Context, id uint) (int, error) {
+	ifMatch := strings.TrimSpace(c.Gin.GetHeader("If-Match"))
+
+	if ifMatch == "" || ifMatch == "*" {
+		return backend.AnyRevision, nil
+	}
+
+	if !strings.HasPrefix(ifMatch, `"`) {
+		return 0, errIfMatch(ifMatch)
+	}
+
+	if rev, err := strconv.Atoi(strings.Trim(ifMatch, `"`)); err == nil {
+		if rev < 0 {
+			return 0, errIfMatch(ifMatch)
+		}
+		return rev, nil
+	}
+
+	res, data, err := getDocumentationBody(c, id)
+	if err != nil {
+		return 0, err
+	}
+
+	if buildContentETag(data) != ifMatch {
+		return 0, errIfMatch(ifMatch)
+	}
+
+	return res.Revision, nil
+}
+
+//=============================================================================
+
+func errIfMatch(ifMatch string) error {
+	return req.AppError{
+		Code   : http.StatusPreconditionFailed,
+		Message: "If-Match header does not match any revision: "+ ifMatch,
+	}
+}
+
+//=============================================================================
+//=== The format can be forced with ?format=, otherwise it is negotiated with
+//=== the Accept header. Returns nil if the client accepts any format
+
+func getChartFormats(c *auth.Context) ([]string, error) {
+	if format := c.Gin.Query("format"); format != "" {
+		if !slices.Contains(backend.ChartFormats, format) {
+			return nil, req.AppError{
+				Code   : http.StatusBadRequest,
+				Message: "Invalid chart format (allowed are png, svg, webp): "+ format,
+			}
+		}
+
+		return []string{ format }, nil
+	}
+
+	accept := c.Gin.GetHeader("Accept")
+	if accept == "" {
+		return nil, nil
+	}
+
+	formats := negotiateChartFormats(accept)
+	if len(formats) == 0 {
+		return nil, req.AppError{
+			Code   : http.StatusNotAcceptable,
+			Message: "Equity charts are available as image/png, image/svg+xml or image/webp",
+		}
+	}
+
+	return formats, nil
+}
+
+//=============================================================================
+//=== Returns the accepted chart formats, ordered by quality. Wildcards add
+//=== the formats not explicitly listed, while q=0 excludes a format
+
+func negotiateChartFormats(accept string) []string {
+	type entry struct {
+		formats []string
+		quality float64
+	}
+
+	var entries  []entry
+	excluded := map[string]bool{}
+
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		media  := strings.ToLower(strings.TrimSpace(params[0]))
+		q      := 1.0
+
+		for _, p := range params[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(p), "=")
+			if found && strings.TrimSpace(name) == "q" {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		var formats []string
+
+		switch media {
+		case "image/png":
+			formats = []string{ backend.ChartFormatPNG }
+		case "image/svg+xml":
+			formats = []string{ backend.ChartFormatSVG }
+		case "image/webp":
+			formats = []string{ backend.ChartFormatWebP }
+		case "image/*", "*/*":
+			formats = backend.ChartFormats
+		}
+
+		if q <= 0 {
+			if len(formats) == 1 {
+				excluded[formats[0]] = true
+			}
+			continue
+		}
+
+		if len(formats) > 0 {
+			entries = append(entries, entry{ formats, q })
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+
+	var result []string
+
+	for _, e := range entries {
+		for _, format := range e.formats {
+			if !excluded[format] && !slices.Contains(result, format) {
+				result = append(result, format)
+			}
+		}
+	}
+
+	return result
+}
+
+//=============================================================================